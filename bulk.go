@@ -0,0 +1,236 @@
+package cube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// The description of an element to create with Dim.AddElems.
+type ElemSpec struct {
+	Name   string  // Name of the element
+	Type   int     // Type of the element (ElemNumeric, ElemString or ElemConsolidated), numeric if 0
+	Parent string  // Name of the parent, the element is a root if empty
+	Weight float64 // Weight of the element in the parent, 1 if 0
+}
+
+// Error type for the elements that could not be created.
+type ElemsErr struct {
+	// A map of error for each index of the element specs.
+	ErrorMap map[int]error
+}
+
+func (e *ElemsErr) Error() string {
+	b := bytes.NewBufferString("elem errors occured:")
+	for _, i := range e.indexes() {
+		b.WriteString(fmt.Sprintf("\n[%d] %s", i, e.ErrorMap[i]))
+	}
+	return b.String()
+}
+
+// Return the errors of the elements, ordered by spec index.
+func (e *ElemsErr) Unwrap() []error {
+	var errs []error
+	for _, i := range e.indexes() {
+		errs = append(errs, e.ErrorMap[i])
+	}
+	return errs
+}
+
+// Return the sorted spec indexes of the errors.
+func (e *ElemsErr) indexes() []int {
+	var idx []int
+	for i := range e.ErrorMap {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// Adds many elements to the dimension with a request for each element type and one for each parent.
+// The elements that cannot be created are reported in an *ElemsErr by spec index, the others are created anyway.
+func (d *Dim) AddElems(ctx context.Context, specs []ElemSpec) error {
+	if d.elems.Empty() {
+		err := d.init(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	var errs = make(map[int]error)
+	var valid = make(map[string]int)
+	for i := range specs {
+		s := &specs[i]
+		if s.Name == "" {
+			errs[i] = fmt.Errorf("empty name")
+			continue
+		}
+		if j, ok := valid[s.Name]; ok {
+			errs[i] = fmt.Errorf("duplicate of %q at index %d", s.Name, j)
+			continue
+		}
+		if d.elems.Name(s.Name) != nil {
+			errs[i] = fmt.Errorf("element %q exists in dimension %q", s.Name, d.Data.Name)
+			continue
+		}
+		valid[s.Name] = i
+	}
+	// the consolidated elements are created first, so the failed parents are known before their children
+	var types = make(map[int][]int)
+	var order = []int{ElemConsolidated}
+	for i := range specs {
+		s := &specs[i]
+		if j, ok := valid[s.Name]; !ok || j != i {
+			continue
+		}
+		if s.Parent != "" && d.elems.Name(s.Parent) == nil {
+			j, ok := valid[s.Parent]
+			if !ok {
+				errs[i] = &ErrMissElem{d.Data.Name, s.Parent}
+				delete(valid, s.Name)
+				continue
+			}
+			if specs[j].Type != ElemConsolidated {
+				errs[i] = fmt.Errorf("parent %q is not consolidated", s.Parent)
+				delete(valid, s.Name)
+				continue
+			}
+		}
+		t := s.Type
+		if t == 0 {
+			t = ElemNumeric
+		}
+		if _, ok := types[t]; !ok && t != ElemConsolidated {
+			order = append(order, t)
+		}
+		types[t] = append(types[t], i)
+	}
+	for _, t := range order {
+		var names []string
+		var batch []int
+		for _, i := range types[t] {
+			s := &specs[i]
+			if s.Parent != "" && d.elems.Name(s.Parent) == nil {
+				if _, ok := valid[s.Parent]; !ok {
+					errs[i] = fmt.Errorf("parent %q not created", s.Parent)
+					delete(valid, s.Name)
+					continue
+				}
+			}
+			names = append(names, s.Name)
+			batch = append(batch, i)
+		}
+		if len(batch) == 0 {
+			continue
+		}
+		p := params{}
+		p.Add("dimension", strconv.Itoa(d.Data.Id))
+		p.List("name_elements", names...)
+		p.Add("type", strconv.Itoa(t))
+		if _, pErr := d.doRequest(ctx, "/element/create_bulk", p); pErr != nil {
+			for _, i := range batch {
+				errs[i] = pErr
+				delete(valid, specs[i].Name)
+			}
+		}
+	}
+	if err := d.mergeElems(ctx); err != nil {
+		return err
+	}
+	var parents = make(map[string][]int)
+	var porder []string
+	for i := range specs {
+		s := &specs[i]
+		if j, ok := valid[s.Name]; !ok || j != i || s.Parent == "" {
+			continue
+		}
+		if _, ok := parents[s.Parent]; !ok {
+			porder = append(porder, s.Parent)
+		}
+		parents[s.Parent] = append(parents[s.Parent], i)
+	}
+	for _, name := range porder {
+		var children []*ElemSpec
+		for _, i := range parents[name] {
+			children = append(children, &specs[i])
+		}
+		if err := d.appendChildren(ctx, name, children); err != nil {
+			for _, i := range parents[name] {
+				errs[i] = err
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &ElemsErr{ErrorMap: errs}
+	}
+	return nil
+}
+
+// Adds to the cache the elements of the dimension that are missing, leaving the others untouched.
+func (d *Dim) mergeElems(ctx context.Context) error {
+	p := make(params)
+	p.Add("dimension", strconv.Itoa(d.Data.Id))
+	rows, err := d.doRequest(ctx, "/dimension/elements", p)
+	if err != nil {
+		return fmt.Errorf("elems merge: %w", err)
+	}
+	var added []*Elem
+	for i := 0; i < len(rows); i++ {
+		var el Elem
+		err := rows[i].Unmarshal(&el)
+		if err != nil {
+			return fmt.Errorf("elems merge: bad row %d (%w)", i, err)
+		}
+		if d.elems.Id(el.Id()) != nil {
+			continue
+		}
+		el.dim = d
+		d.elems.Add(&el)
+		added = append(added, &el)
+	}
+	for _, el := range added {
+		err := el.init(&d.elems)
+		if err != nil {
+			return err
+		}
+		if len(el.parents) == 0 {
+			d.roots.Add(el)
+		}
+	}
+	return nil
+}
+
+// Appends the elements described by specs to the given parent, updating the cached links.
+func (d *Dim) appendChildren(ctx context.Context, parentName string, specs []*ElemSpec) error {
+	parent, err := d.Elem(parentName)
+	if err != nil {
+		return err
+	}
+	p := params{}
+	p.Add("dimension", strconv.Itoa(d.Data.Id))
+	p.Add("element", strconv.Itoa(parent.Id()))
+	var ids []int
+	var weights []float64
+	for _, s := range specs {
+		el, err := d.Elem(s.Name)
+		if err != nil {
+			return err
+		}
+		w := s.Weight
+		if w == 0 {
+			w = 1
+		}
+		ids = append(ids, el.Id())
+		weights = append(weights, w)
+		p.Add("children", strconv.Itoa(el.Id()))
+		p.Add("weights", strconv.FormatFloat(w, 'f', -1, 64))
+	}
+	if _, pErr := d.doRequest(ctx, "/element/append", p); pErr != nil {
+		return pErr
+	}
+	parent.Data.Type = ElemConsolidated
+	parent.Data.Children = append(parent.Data.Children, ids...)
+	parent.Data.Weights = append(parent.Data.Weights, weights...)
+	return parent.relink()
+}
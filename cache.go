@@ -24,6 +24,25 @@ func (c *cache) Add(v indexable) {
 	c.names[name] = id
 }
 
+func (c *cache) Del(id int) {
+	v, ok := c.objects[id]
+	if !ok {
+		return
+	}
+	delete(c.objects, id)
+	if c.names[v.Name()] == id {
+		delete(c.names, v.Name())
+	}
+}
+
+// Updates the name index of an object already in the cache.
+func (c *cache) Rename(v indexable, old string) {
+	if c.names[old] == v.Id() {
+		delete(c.names, old)
+	}
+	c.Add(v)
+}
+
 func (c *cache) Id(id int) indexable {
 	return c.objects[id]
 }
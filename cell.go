@@ -1,28 +1,124 @@
 package cube
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 )
 
-// An invalid attemp of setting or updating the value of a consolidate cell.
+// An invalid attemp of setting or updating the value of a consolidate cell without a splash mode.
 var ErrConsolidate = errors.New("cellgroup contains consolidate cells")
 
+// Cell value types.
+const (
+	CellNumeric = 1
+	CellString  = 2
+)
+
 // A Palo Cell.
 type Cell struct {
 	Data struct {
 		Type  int     //Type of the value (1=NUMERIC, 2=STRING)
 		Exist int     //1 if at least one base cell for the path exists
-		Value float64 //Value of the cell
+		Value float64 //Value of the cell, 0 for string cells
+		Text  string  //Value of a string cell, empty for numeric cells
 	}
 	Path Coord //cell coordinates
 }
 
+// Populates the cell from a row that starts with type, exists and value.
+func (c *Cell) unmarshal(row resultRow) error {
+	var raw struct {
+		Data struct {
+			Type, Exist int
+			Value       string
+		}
+	}
+	if err := row.Unmarshal(&raw); err != nil {
+		return err
+	}
+	c.Data.Type, c.Data.Exist = raw.Data.Type, raw.Data.Exist
+	c.Data.Value, c.Data.Text = 0, ""
+	if c.IsString() {
+		c.Data.Text = raw.Data.Value
+		return nil
+	}
+	if raw.Data.Value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(raw.Data.Value, 64)
+	if err != nil {
+		return err
+	}
+	c.Data.Value = f
+	return nil
+}
+
+// True if the cell contains a string.
+func (c *Cell) IsString() bool {
+	return c.Data.Type == CellString
+}
+
+// Return the numeric value of the cell, 0 for string cells.
+func (c *Cell) Float() float64 {
+	return c.Data.Value
+}
+
+// Return the string value of the cell, the textual representation for numeric cells.
+func (c *Cell) Str() string {
+	if c.IsString() {
+		return c.Data.Text
+	}
+	return strconv.FormatFloat(c.Data.Value, 'f', -1, 64)
+}
+
+// Return the value of the cell, a string or a float64.
+func (c *Cell) Value() interface{} {
+	if c.IsString() {
+		return c.Data.Text
+	}
+	return c.Data.Value
+}
+
+// Formats a value for a request, strings are always quoted.
+func cellValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// The way a value written to a consolidated cell is distributed to its base cells.
+type Splash int
+
+const (
+	SplashNone    Splash = iota // No distribution, consolidated cells cannot be written
+	SplashDefault               // The value is distributed proportionally to the existing base values
+	SplashAdd                   // The value is added to every base cell
+	SplashSet                   // The value is set to every base cell
+)
+
 // A group of cells that can be manipulated or read.
 type CellGroup struct {
 	cube    *Cube
 	cells   []Cell
 	hasCons bool
+	splash  Splash
+}
+
+// Return a copy of the cellgroup that writes consolidated cells using the given splash mode.
+func (cg *CellGroup) WithSplash(s Splash) *CellGroup {
+	c := *cg
+	c.splash = s
+	return &c
 }
 
 // True if there is any consolidate cell.
@@ -37,24 +133,54 @@ func (cg *CellGroup) Cells() []Cell {
 
 // Fetch the cells values from the cube.
 func (cg *CellGroup) Fetch() error {
-	p := params{}
-	for _, c := range cg.cells {
-		p.Path("paths", []string{c.Path.String()})
-	}
-	rows, err := cg.cube.doRequest("/cell/values", p)
-	if err != nil {
-		return fmt.Errorf("cells: %s", err)
+	return cg.FetchContext(context.Background())
+}
+
+// Fetch the cells values from the cube, the request is bound to the context.
+// Large cellgroups are fetched in chunks, an error is a *CellsErr.
+func (cg *CellGroup) FetchContext(ctx context.Context) error {
+	c := cg.cube.client()
+	chunks := splitChunks(len(cg.cells), c.maxRequest, func(i int) int {
+		return encodedSize(cg.cells[i].Path.String())
+	})
+	errs := runChunks(chunks, c.workers, func(_ int, ch chunk) error {
+		p := params{}
+		for i := ch.first; i <= ch.last; i++ {
+			p.Path("paths", []string{cg.cells[i].Path.String()})
+		}
+		rows, err := cg.cube.doRequest(ctx, "/cell/values", p)
+		if err != nil {
+			return err
+		}
+		if l, ex := len(rows), ch.last-ch.first+1; l != ex {
+			return fmt.Errorf("rows length %d, expected %d", l, ex)
+		}
+		for i := range rows {
+			if err := cg.cells[ch.first+i].unmarshal(rows[i]); err != nil {
+				return fmt.Errorf("cell: bad row %d (%w)", ch.first+i, err)
+			}
+		}
+		return nil
+	})
+	return cellsErr(len(cg.cells), errs, nil)
+}
+
+// Builds a *CellsErr from the chunk errors and the failed cells, nil if all went well.
+func cellsErr(total int, errs []*ChunkErr, failed []int) error {
+	if len(errs) == 0 && len(failed) == 0 {
+		return nil
 	}
-	for i := range cg.cells {
-		if err := rows[i].Unmarshal(&cg.cells[i]); err != nil {
-			return fmt.Errorf("cell: bad row %d (%s)", i, err)
+	for _, err := range errs {
+		for i := err.First; i <= err.Last; i++ {
+			failed = append(failed, i)
 		}
 	}
-	return nil
+	sort.Ints(failed)
+	return &CellsErr{Done: total - len(failed), Failed: failed, Chunks: errs}
 }
 
-func (cg *CellGroup) change(values []interface{}, add, bulk bool) error {
-	if cg.hasCons {
+func (cg *CellGroup) change(ctx context.Context, values []interface{}, add, bulk bool) error {
+	if cg.hasCons && cg.splash == SplashNone {
 		return ErrConsolidate
 	}
 	if l := len(values); bulk && l != 1 {
@@ -62,52 +188,85 @@ func (cg *CellGroup) change(values []interface{}, add, bulk bool) error {
 	} else if ex := len(cg.cells); !bulk && ex != l {
 		return fmt.Errorf("values length %d, expected %d", l, ex)
 	}
-	p := params{}
-	if add {
-		p.Add("add", "1")
-	}
-	for i, c := range cg.cells {
-		var j = 0
-		if !bulk {
-			j = i
+	var value = func(i int) string {
+		if bulk {
+			return cellValue(values[0])
 		}
-		p.Path("values", []string{fmt.Sprintf("%v", values[j])})
-		p.Path("paths", []string{c.Path.String()})
-	}
-	rows, err := cg.cube.doRequest("/cell/replace_bulk", p)
-	if err != nil {
-		return fmt.Errorf("cells: %s", err)
+		return cellValue(values[i])
 	}
+	c := cg.cube.client()
+	chunks := splitChunks(len(cg.cells), c.maxRequest, func(i int) int {
+		return encodedSize(cg.cells[i].Path.String()) + encodedSize(value(i))
+	})
+	var mu sync.Mutex
 	var badrows []int
-	for i := range rows {
-		if !rows[i].HasField(0) || rows[i][0].String() != "1" {
-			badrows = append(badrows, i)
+	errs := runChunks(chunks, c.workers, func(_ int, ch chunk) error {
+		p := params{}
+		if add {
+			p.Add("add", "1")
 		}
-	}
-	if len(badrows) > 0 {
-		return fmt.Errorf("cannot insert rows %v", badrows)
-	}
-	return nil
+		if cg.splash != SplashNone {
+			p.Add("splash", strconv.Itoa(int(cg.splash)))
+		}
+		for i := ch.first; i <= ch.last; i++ {
+			p.Path("values", []string{value(i)})
+			p.Path("paths", []string{cg.cells[i].Path.String()})
+		}
+		rows, err := cg.cube.doRequest(ctx, "/cell/replace_bulk", p)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		// a missing row means the cell was not written
+		for i := 0; i <= ch.last-ch.first; i++ {
+			if i >= len(rows) || !rows[i].HasField(0) || rows[i][0].String() != "1" {
+				badrows = append(badrows, ch.first+i)
+			}
+		}
+		return nil
+	})
+	return cellsErr(len(cg.cells), errs, badrows)
 }
 
 // Sets a value for each cell.
 func (cg *CellGroup) Set(values []interface{}) error {
-	return cg.change(values, false, false)
+	return cg.SetContext(context.Background(), values)
+}
+
+// Sets a value for each cell, the request is bound to the context.
+func (cg *CellGroup) SetContext(ctx context.Context, values []interface{}) error {
+	return cg.change(ctx, values, false, false)
 }
 
 // Sets the same value for all cells.
 func (cg *CellGroup) SetAll(value interface{}) error {
-	return cg.change([]interface{}{value}, false, true)
+	return cg.SetAllContext(context.Background(), value)
+}
+
+// Sets the same value for all cells, the request is bound to the context.
+func (cg *CellGroup) SetAllContext(ctx context.Context, value interface{}) error {
+	return cg.change(ctx, []interface{}{value}, false, true)
 }
 
 //Add a value to each cell.
 func (cg *CellGroup) Add(values []interface{}) error {
-	return cg.change(values, true, false)
+	return cg.AddContext(context.Background(), values)
+}
+
+// Adds a value to each cell, the request is bound to the context.
+func (cg *CellGroup) AddContext(ctx context.Context, values []interface{}) error {
+	return cg.change(ctx, values, true, false)
 }
 
 // Adds the same value to all cells.
 func (cg *CellGroup) AddAll(value interface{}) error {
-	return cg.change([]interface{}{value}, true, true)
+	return cg.AddAllContext(context.Background(), value)
+}
+
+// Adds the same value to all cells, the request is bound to the context.
+func (cg *CellGroup) AddAllContext(ctx context.Context, value interface{}) error {
+	return cg.change(ctx, []interface{}{value}, true, true)
 }
 
 // Joins two cellgroups into one.
@@ -116,9 +275,10 @@ func (cg *CellGroup) Append(c CellGroup) *CellGroup {
 		cube:    cg.cube,
 		cells:   append(cg.cells, c.Cells()...),
 		hasCons: cg.hasCons || c.HasCons(),
+		splash:  cg.splash,
 	}
 }
 
 func (cg *CellGroup) String() string {
-	return fmt.Sprintf("<cellgroup cells:%d canset:%v/>", len(cg.cells), !cg.hasCons)
+	return fmt.Sprintf("<cellgroup cells:%d canset:%v/>", len(cg.cells), !cg.hasCons || cg.splash != SplashNone)
 }
@@ -0,0 +1,131 @@
+package cube
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+const (
+	defaultMaxRequestSize = 4096
+	requestOverhead       = 256 // space reserved for the url and the other parameters
+)
+
+// Sets the maximum encoded size of the cells in a request, larger cellgroups are split in chunks.
+func WithMaxRequestSize(n int) Option {
+	return func(c *client) {
+		c.maxRequest = n
+	}
+}
+
+// Sets the number of chunks of a cellgroup sent concurrently.
+func WithWorkers(n int) Option {
+	return func(c *client) {
+		c.workers = n
+	}
+}
+
+// A portion of a cellgroup sent with a single request.
+type chunk struct {
+	first, last int // indexes of the first and the last cell
+}
+
+// Splits n items in chunks, size returns the encoded size of an item.
+func splitChunks(n, max int, size func(i int) int) []chunk {
+	if max <= requestOverhead {
+		max = defaultMaxRequestSize
+	}
+	max -= requestOverhead
+	var r []chunk
+	for first, l, i := 0, 0, 0; i < n; i++ {
+		s := size(i)
+		if i > first && l+s > max {
+			r = append(r, chunk{first, i - 1})
+			first, l = i, 0
+		}
+		l += s
+		if i == n-1 {
+			r = append(r, chunk{first, i})
+		}
+	}
+	return r
+}
+
+// Encoded size of a value joined to other ones.
+func encodedSize(s string) int {
+	return len(url.QueryEscape(s)) + 3
+}
+
+// Executes f for each chunk using the given number of workers.
+func runChunks(chunks []chunk, workers int, f func(n int, c chunk) error) []*ChunkErr {
+	if workers < 1 {
+		workers = 1
+	}
+	var errs = make([]*ChunkErr, len(chunks))
+	var wg sync.WaitGroup
+	var queue = make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range queue {
+				if err := f(n, chunks[n]); err != nil {
+					errs[n] = &ChunkErr{Chunk: n, First: chunks[n].first, Last: chunks[n].last, Err: err}
+				}
+			}
+		}()
+	}
+	for n := range chunks {
+		queue <- n
+	}
+	close(queue)
+	wg.Wait()
+	var r []*ChunkErr
+	for _, err := range errs {
+		if err != nil {
+			r = append(r, err)
+		}
+	}
+	return r
+}
+
+// Error type for a failed chunk of a cellgroup.
+type ChunkErr struct {
+	Chunk       int // Index of the chunk
+	First, Last int // Indexes of the first and the last cell of the chunk
+	Err         error
+}
+
+func (e *ChunkErr) Error() string {
+	return fmt.Sprintf("chunk %d (cells %d-%d): %s", e.Chunk, e.First, e.Last, e.Err)
+}
+
+func (e *ChunkErr) Unwrap() error {
+	return e.Err
+}
+
+// Error type for a cellgroup operation that failed partially.
+type CellsErr struct {
+	Done   int         // Number of cells read or written
+	Failed []int       // Indexes of the cells that failed
+	Chunks []*ChunkErr // Errors of the failed chunks
+}
+
+func (e *CellsErr) Error() string {
+	b := bytes.NewBufferString(fmt.Sprintf("cells errors occured (%d done, %d failed):", e.Done, len(e.Failed)))
+	for _, err := range e.Chunks {
+		b.WriteString(fmt.Sprintf("\n%s", err))
+	}
+	b.WriteString(fmt.Sprintf("\nfailed cells %v", e.Failed))
+	return b.String()
+}
+
+// Return the errors of the failed chunks.
+func (e *CellsErr) Unwrap() []error {
+	var errs []error
+	for _, err := range e.Chunks {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -1,181 +1,222 @@
 package cube
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
+	"time"
 )
 
-// An error in the execution of a request
-type PaloError struct {
-	Code    int // Code 0 means an internal package error
-	Name    string
-	Message string
+// Palo Server configuation
+type Config struct {
+	User, Pwd, Host, Port, Db string
 }
 
-func (err *PaloError) Error() string {
-	return fmt.Sprintf("%s (%v): %s", err.Name, err.Code, err.Message)
+type client struct {
+	logger    Logger
+	bodyLimit int
+	conf      Config
+	http      *http.Client
+	transport http.RoundTripper
+	scheme    string
+	baseUrl   string
+	session
+
+	maxRequest    int
+	workers       int
+	postThreshold int
+	retryPolicy   RetryPolicy
+	breaker       *breaker
+	hooks         Hooks
+	instrument    Instrument
 }
 
-const codeInvalidSession = 1015
+// An option that changes the behaviour of the client.
+type Option func(*client)
 
-// Palo Server configuation
-type Config struct {
-	User, Pwd, Host, Port, Db string
+// Uses the given http client for the requests, allowing to set timeouts, proxies, TLS and pool settings.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *client) {
+		c.http = hc
+	}
 }
 
-type sampleData struct {
-	Data struct {
-		Id   int
-		Name string
+// Uses the given transport for the requests, also with the http client of WithHTTPClient.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *client) {
+		c.transport = rt
 	}
 }
 
-type client struct {
-	io.Writer
-	conf    Config
-	baseUrl string
-	sid     string
-	dbId    string
+// Uses the given scheme (http or https) to connect to the server.
+func WithScheme(scheme string) Option {
+	return func(c *client) {
+		c.scheme = scheme
+	}
+}
+
+const defaultPostThreshold = 2048
+
+// Sets the query length over which a read request is sent as POST body instead of GET.
+func WithPostThreshold(n int) Option {
+	return func(c *client) {
+		c.postThreshold = n
+	}
 }
 
-func newClient(conf Config, w io.Writer) (*client, error) {
-	var c = client{Writer: w, conf: conf}
-	c.baseUrl = fmt.Sprintf("http://%s:%s", c.conf.Host, c.conf.Port)
-	err := c.Login()
+func newClient(ctx context.Context, conf Config, opts ...Option) (*client, error) {
+	var c = client{bodyLimit: defaultBodyLimit, conf: conf, http: http.DefaultClient, scheme: "http", maxRequest: defaultMaxRequestSize, workers: 1, postThreshold: defaultPostThreshold}
+	c.maxRelogin = defaultMaxRelogin
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.transport != nil {
+		hc := *c.http
+		hc.Transport = c.transport
+		c.http = &hc
+	}
+	c.baseUrl = fmt.Sprintf("%s://%s:%s", c.scheme, c.conf.Host, c.conf.Port)
+	err := c.Login(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return &c, nil
 }
 
-func (c *client) Login() error {
-	p := make(params)
-	p.Add("user", c.conf.User)
-	p.Add("password", c.conf.Pwd)
-	rows, err := c.doRequest("/server/login", p)
-	if err != nil {
-		return err
-	}
-	var loginData struct {
-		Data struct{ Session, Time string }
+// Endpoints that only read data.
+var readEndpoints = map[string]bool{
+	"/server/databases":    true,
+	"/server/info":         true,
+	"/database/info":       true,
+	"/database/cubes":      true,
+	"/database/dimensions": true,
+	"/dimension/info":      true,
+	"/dimension/elements":  true,
+	"/element/info":        true,
+	"/cube/info":           true,
+	"/cell/value":          true,
+	"/cell/values":         true,
+	"/cell/area":           true,
+	"/cell/export":         true,
+}
+
+// Creates the request for the endpoint, login, write endpoints and long queries are sent as POST body.
+func (c *client) newRequest(endpoint, query string) (*http.Request, error) {
+	url := c.baseUrl + endpoint
+	if readEndpoints[endpoint] && len(query) <= c.postThreshold {
+		return http.NewRequest("GET", fmt.Sprintf("%s?%s", url, query), nil)
 	}
-	if err := rows[0].Unmarshal(&loginData); err != nil {
-		return err
+	req, err := http.NewRequest("POST", url, strings.NewReader(query))
+	if err != nil {
+		return nil, err
 	}
-	c.sid = loginData.Data.Session
-	return nil
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
 }
 
-func (c *client) Write(text ...string) {
-	if c.Writer != nil {
-		for _, s := range text {
-			c.Writer.Write([]byte(s))
+// Executes a request to palo and returns the rows, the request is cancelled with the context.
+// An expired session is renewed and the request repeated, up to the maximum number of logins.
+func (c *client) doRequest(ctx context.Context, url string, p params) (result []resultRow, pe *PaloError) {
+	if p == nil {
+		p = make(params)
+	}
+	for attempt := 0; ; attempt++ {
+		sid, gen, err := c.current()
+		if err != nil {
+			return nil, err
+		}
+		p.Set("sid", sid)
+		result, pe = c.retry(ctx, url, p)
+		if pe == nil || pe.Code != ErrInvalidSession.Code || attempt >= c.maxRelogin {
+			return result, pe
+		}
+		if err := c.relogin(ctx, gen); err != nil {
+			return nil, wrapErr("login", err)
 		}
-		c.Writer.Write([]byte("\n"))
 	}
 }
 
-// Executes a request to palo and returns the rows.
-func (c *client) doRequest(url string, p params) (result []resultRow, pe *PaloError) {
-	if p == nil {
-		p = make(params)
+// Wraps an error of the http client, replacing its url (that contains the session id) with the endpoint.
+func requestErr(endpoint string, err error) *PaloError {
+	var ue *url.Error
+	if !errors.As(err, &ue) {
+		return wrapErr("request error", err)
 	}
-	p.Set("sid", c.sid)
-	p.Set("database", c.dbId)
-	url = fmt.Sprintf("%s%s?%s", c.baseUrl, url, p.String())
-	c.Write("Request: ", url)
-	resp, err := http.Get(url)
+	return &PaloError{Message: fmt.Sprintf("request error: %s %q: %s", ue.Op, endpoint, ue.Err), err: ue.Err}
+}
+
+// Sends a request and returns the rows, without handling the session.
+func (c *client) send(ctx context.Context, url string, p params) (result []resultRow, pe *PaloError) {
+	query := p.String()
+	req, err := c.newRequest(url, query)
 	if err != nil {
-		return nil, internalErr(fmt.Sprintf("request error: %s", err))
-	}
-	data, err := ioutil.ReadAll(resp.Body)
+		return nil, requestErr(url, err)
+	}
+	var status int
+	var data bodyBuffer
+	if c.logger != nil {
+		data.limit = c.bodyLimit
+	}
+	info := newRequestInfo(url, req.Method, p, len(query))
+	if c.instrument != nil {
+		ctx = c.instrument.Start(ctx, info)
+	}
+	start := time.Now()
+	defer func() {
+		d := time.Since(start)
+		c.logRequest(url, req.Method, p, status, d, len(result), &data, pe)
+		if c.instrument != nil {
+			info.ResponseBytes, info.Rows, info.Status, info.Duration = data.size, len(result), status, d
+			if pe != nil {
+				info.Err = pe
+			}
+			c.instrument.End(ctx, info)
+		}
+	}()
+	resp, err := c.http.Do(req.WithContext(ctx))
 	if err != nil {
-		return nil, internalErr(fmt.Sprintf("body read error: %s", err))
+		pe := requestErr(url, err)
+		pe.transient = ctx.Err() == nil
+		return nil, pe
+	}
+	defer resp.Body.Close()
+	status = resp.StatusCode
+	r := NewReader(io.TeeReader(resp.Body, &data))
+	if resp.StatusCode >= 500 {
+		pe := wrapErr("server error", StatusError(resp.StatusCode))
+		pe.transient = true
+		return nil, pe
 	}
-	c.Write("Response: ", string(data))
 	if resp.StatusCode == 400 {
-		rd, err := newResultRow(string(data))
+		record, err := r.Read()
 		if err != nil {
-			return nil, internalErr(fmt.Sprintf("bad row: %q", string(data)))
+			return nil, wrapErr("bad row", err)
 		}
 		var pe struct {
 			Data PaloError
 		}
-		err = rd.Unmarshal(&pe)
+		err = newRecordRow(record).Unmarshal(&pe)
 		if err != nil {
-			return nil, internalErr(err.Error())
-		}
-		if pe.Data.Code == codeInvalidSession {
-			err = c.Login()
-			if err != nil {
-				return nil, internalErr(fmt.Sprintf("login: %s", err))
-			}
-			return c.doRequest(url, p)
+			return nil, wrapErr("bad error row", err)
 		}
 		return nil, &pe.Data
 	}
-	resRows := strings.Split(string(data), "\n")
-	if len(resRows) == 0 {
-		return nil, internalErr(fmt.Sprintf("no rows found: %q", resRows))
-	}
-	if resRows[len(resRows)-1] == "" {
-		resRows = resRows[:len(resRows)-1]
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, wrapErr("unexpected status", StatusError(resp.StatusCode))
 	}
-	for i := range resRows {
-		row, err := newResultRow(resRows[i])
-		if err != nil {
-			return nil, internalErr(fmt.Sprintf("row %d: %s", i, err.Error()))
-		}
-		result = append(result, row)
-	}
-	return result, nil
-}
-
-func (c *client) GetCube(cubeName string, isAttribute bool) (*Cube, error) {
-	rows, err := c.doRequest("/server/databases", nil)
-	if err != nil {
-		return nil, fmt.Errorf("request error")
-	}
-	for i := 0; i < len(rows); i++ {
-		var d sampleData
-		err := rows[i].Unmarshal(&d)
-		if err != nil {
-			return nil, err
-		}
-		if c.conf.Db == d.Data.Name {
-			c.dbId = strconv.Itoa(d.Data.Id)
+	for i := 0; ; i++ {
+		record, err := r.Read()
+		if err == io.EOF {
 			break
 		}
-	}
-	if c.dbId == "" {
-		return nil, fmt.Errorf("db %s not found", c.conf.Db)
-	}
-	p := params{}
-	if isAttribute {
-		p.Add("show_attribute", "1")
-	}
-	rows, err = c.doRequest("/database/cubes", p)
-	if err != nil {
-		return nil, fmt.Errorf("request error")
-	}
-	var cb Cube
-	for i := 0; i < len(rows); i++ {
-		err := rows[i].Unmarshal(&cb)
 		if err != nil {
-			return nil, err
+			return nil, wrapErr(fmt.Sprintf("row %d", i), err)
 		}
-		if cubeName == cb.Data.Name {
-			break
-		}
-	}
-	if cubeName != cb.Data.Name {
-		return nil, fmt.Errorf("cube %s not found", cubeName)
+		result = append(result, newRecordRow(record))
 	}
-	cb.isAttribute = isAttribute
-	cb.client = c
-	return &cb, nil
+	return result, nil
 }
@@ -3,6 +3,8 @@ package cube
 import (
 	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // A Palo cube coordinates.
@@ -20,6 +22,19 @@ func (c Coord) String() string {
 	return b.String()
 }
 
+// Parses csv coordinates.
+func parseCoord(s string) (Coord, error) {
+	var c Coord
+	for _, v := range resultField(s).Array() {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("bad coordinate %q", s)
+		}
+		c = append(c, n)
+	}
+	return c, nil
+}
+
 // An area of coordinates which can specify multiple values for any dimension.
 type CoordArea map[int][]int
 
@@ -50,6 +65,24 @@ func (c CoordArea) Split(dims []int) ([]Coord, error) {
 	return coords, nil
 }
 
+// Returns the area as the area parameter of a request, ordered by dimension:
+// the dimensions are separated by commas and the element ids of each dimension by colons.
+func (c CoordArea) area(dims []int) (string, error) {
+	var r []string
+	for _, dimId := range dims {
+		values, ok := c[dimId]
+		if !ok {
+			return "", fmt.Errorf("dimension %d not found", dimId)
+		}
+		var ids []string
+		for _, v := range values {
+			ids = append(ids, strconv.Itoa(v))
+		}
+		r = append(r, strings.Join(ids, ":"))
+	}
+	return strings.Join(r, ","), nil
+}
+
 type CoordErr struct {
 	// A map of error for each coordinate.
 	ErrorMap map[string]error
@@ -0,0 +1,191 @@
+package cube
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Errors returned in a *ParseError.
+var (
+	ErrQuote     = errors.New("unexpected quote in unquoted field")
+	ErrBareQuote = errors.New("unexpected character after closing quote")
+	ErrOpenQuote = errors.New("quoted field not closed")
+)
+
+// Returned by Writer.Write for a record without fields, that cannot be read back.
+var ErrEmptyRecord = errors.New("empty record")
+
+// A Palo csv syntax error, with its position.
+type ParseError struct {
+	Line   int // Line of the error, starting from 1
+	Column int // Column (byte) of the error, starting from 1
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reads records in the Palo csv format: every field ends with a semicolon, every record with a new line.
+// Fields can be quoted, quotes in quoted fields are doubled, quoted fields can contain new lines.
+type Reader struct {
+	r    *bufio.Reader
+	line int
+	col  int
+}
+
+// Return a reader that reads records from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), line: 1}
+}
+
+func (r *Reader) readByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b == '\n' {
+		r.line++
+		r.col = 0
+	} else {
+		r.col++
+	}
+	return b, nil
+}
+
+func (r *Reader) error(err error) error {
+	return &ParseError{Line: r.line, Column: r.col, Err: err}
+}
+
+// Reads the next record, empty lines are skipped. Returns io.EOF when there are no more records.
+func (r *Reader) Read() ([]string, error) {
+	var record []string
+	var field bytes.Buffer
+	var quoted, closed, started bool
+	for {
+		b, err := r.readByte()
+		if err == io.EOF {
+			switch {
+			case quoted && !closed:
+				return nil, r.error(ErrOpenQuote)
+			case started:
+				record = append(record, field.String())
+			}
+			if record == nil {
+				return nil, io.EOF
+			}
+			return record, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case quoted && !closed:
+			if b != '"' {
+				field.WriteByte(b)
+				continue
+			}
+			if p, err := r.r.Peek(1); err == nil && p[0] == '"' {
+				r.readByte()
+				field.WriteByte('"')
+				continue
+			}
+			closed = true
+		case b == ';':
+			record = append(record, field.String())
+			field.Reset()
+			quoted, closed, started = false, false, false
+		case b == '\r':
+			if p, err := r.r.Peek(1); err == nil && p[0] == '\n' {
+				continue
+			}
+			fallthrough
+		case b == '\n':
+			if started {
+				record = append(record, field.String())
+			}
+			if record != nil {
+				return record, nil
+			}
+			quoted, closed, started = false, false, false
+		case closed:
+			return nil, r.error(ErrBareQuote)
+		case b == '"':
+			if started {
+				return nil, r.error(ErrQuote)
+			}
+			started, quoted = true, true
+		default:
+			started = true
+			field.WriteByte(b)
+		}
+	}
+}
+
+// Reads all the remaining records.
+func (r *Reader) ReadAll() ([][]string, error) {
+	var records [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// Writes records in the Palo csv format.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// Return a writer that writes records to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Writes a record, fields with semicolons, quotes or new lines are quoted.
+// A record without fields is an ErrEmptyRecord, since the reader skips empty lines.
+func (w *Writer) Write(record []string) error {
+	if len(record) == 0 {
+		return ErrEmptyRecord
+	}
+	for _, f := range record {
+		if strings.ContainsAny(f, ";\"\r\n") {
+			f = quote(f)
+		}
+		if _, err := w.w.WriteString(f); err != nil {
+			return err
+		}
+		if err := w.w.WriteByte(';'); err != nil {
+			return err
+		}
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Writes all the records and flushes the writer.
+func (w *Writer) WriteAll(records [][]string) error {
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,82 @@
+package cube
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriterEmptyRecord(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write([]string{}); !errors.Is(err, ErrEmptyRecord) {
+		t.Errorf("got %v, expected ErrEmptyRecord", err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		err  error
+		line int
+	}{
+		{"a\"b;\n", ErrQuote, 1},
+		{"1;\n\"a\"b;\n", ErrBareQuote, 2},
+		{"\"open;\n", ErrOpenQuote, 2},
+	} {
+		_, err := NewReader(strings.NewReader(tc.in)).ReadAll()
+		var pe *ParseError
+		if !errors.As(err, &pe) || !errors.Is(err, tc.err) || pe.Line != tc.line {
+			t.Errorf("%q: got %v, expected %v at line %d", tc.in, err, tc.err, tc.line)
+		}
+	}
+}
+
+// Writes the records and reads them back.
+func roundTrip(t *testing.T, records [][]string) [][]string {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).WriteAll(records); err != nil {
+		t.Fatalf("write %q: %s", records, err)
+	}
+	read, err := NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read %q: %s", buf.String(), err)
+	}
+	return read
+}
+
+func FuzzReaderWriter(f *testing.F) {
+	for _, s := range []string{
+		"1;\"a\";\n",
+		"1;\"a;b\";\"c\"\"d\";\n2;;\n",
+		"\"multi\nline\";x;\r\n",
+		"no;end",
+		"a\x1fb\x1ec",
+		"\x1e\x1f",
+		"",
+	} {
+		f.Add(s)
+	}
+	for _, name := range nastyNames {
+		f.Add(name)
+	}
+	f.Fuzz(func(t *testing.T, data string) {
+		// records built from the input, fields split by \x1f and records by \x1e
+		var records [][]string
+		for _, r := range strings.Split(data, "\x1e") {
+			records = append(records, strings.Split(r, "\x1f"))
+		}
+		if read := roundTrip(t, records); !reflect.DeepEqual(read, records) {
+			t.Errorf("records %q read as %q", records, read)
+		}
+		// records parsed from the input are written and read unchanged
+		parsed, err := NewReader(strings.NewReader(data)).ReadAll()
+		if err != nil || parsed == nil {
+			return
+		}
+		if read := roundTrip(t, parsed); !reflect.DeepEqual(read, parsed) {
+			t.Errorf("records %q read as %q", parsed, read)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package cube
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -22,29 +23,44 @@ var roleMap = map[string]func(time.Time) string{
 
 // Return a new cube using the given name and configuration.
 func New(name string, c Config, w io.Writer) (*Cube, error) {
-	client, err := newClient(c, w)
+	return NewContext(context.Background(), name, c, w)
+}
+
+// Return a new cube using the given name and configuration, requests are bound to the context.
+func NewContext(ctx context.Context, name string, c Config, w io.Writer) (*Cube, error) {
+	return Open(ctx, name, c, WithWriter(w))
+}
+
+// Return a new cube using the given name, configuration and client options.
+// It opens a dedicated connection, closed with Cube.Close, use Connect to share it between cubes.
+func Open(ctx context.Context, name string, c Config, opts ...Option) (*Cube, error) {
+	s, err := Connect(ctx, c, opts...)
 	if err != nil {
 		return nil, err
 	}
-	cb, err := client.GetCube(name, false)
+	db, err := s.Database(ctx, c.Db)
 	if err != nil {
+		s.Close()
 		return nil, err
 	}
-	err = cb.init()
+	cb, err := db.Cube(ctx, name)
 	if err != nil {
+		s.Close()
 		return nil, err
 	}
+	cb.owner = true
 	return cb, nil
 }
 
 // An OLAP Cube
 type Cube struct {
-	client      *client
+	db          *Database
 	hash        string
 	tags        map[string]string
 	dims        cache
 	group       map[string][]*Dim
 	isAttribute bool
+	owner       bool // the cube has a dedicated connection
 	Data        struct {
 		Id           int    //Identifier of the cube
 		Name         string //Name of the cube
@@ -58,36 +74,60 @@ type Cube struct {
 	}
 }
 
-func (c *Cube) doRequest(url string, p params) (result []resultRow, pe *PaloError) {
+func (c *Cube) doRequest(ctx context.Context, url string, p params) (result []resultRow, pe *PaloError) {
 	p.Add("cube", fmt.Sprintf("%v", c.Data.Id))
-	return c.client.doRequest(url, p)
+	return c.db.doRequest(ctx, url, p)
+}
+
+func (c *Cube) client() *client {
+	return c.db.server.client
+}
+
+// Return the server of the cube.
+func (c *Cube) Server() *Server {
+	return c.db.server
+}
+
+// Logs out from the server if the cube was opened with New or Open, the cube cannot be used afterwards.
+// The cubes of a shared server are left open, close the server instead.
+func (c *Cube) Close() error {
+	return c.CloseContext(context.Background())
+}
+
+// Logs out from the dedicated server of the cube, the request is bound to the context.
+func (c *Cube) CloseContext(ctx context.Context) error {
+	if !c.owner {
+		return nil
+	}
+	return c.db.server.CloseContext(ctx)
 }
 
-func (c *Cube) init() error {
-	err := c.initDims()
+func (c *Cube) init(ctx context.Context) error {
+	err := c.initDims(ctx)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *Cube) initDims() error {
+func (c *Cube) initDims(ctx context.Context) error {
 	c.dims = newCache()
 	c.group = make(map[string][]*Dim)
 	p := params{}
 	if c.isAttribute {
 		p.Add("show_attribute", "1")
 	}
-	rows, err := c.doRequest("/database/dimensions", p)
+	rows, err := c.doRequest(ctx, "/database/dimensions", p)
 	if err != nil {
-		return fmt.Errorf("dims init: %s", err)
+		return fmt.Errorf("dims init: %w", err)
 	}
 	for i := 0; i < len(rows); i++ {
 		var dm Dim
 		err := rows[i].Unmarshal(&dm)
 		if err != nil {
-			return fmt.Errorf("dims init: bad row %d (%s)", i, err)
+			return fmt.Errorf("dims init: bad row %d (%w)", i, err)
 		}
+		dm.db = c.db
 		if g := dm.tags["group"]; g != "" {
 			c.group[g] = append(c.group[g], &dm)
 		}
@@ -102,6 +142,38 @@ func (c *Cube) initDims() error {
 	return nil
 }
 
+// Updates the dimension caches after a rename, using the previous name and group tag.
+func (c *Cube) renameDim(d *Dim, oldName, oldGroup string) {
+	if c.dims.Id(d.Id()) != nil {
+		c.dims.Rename(d, oldName)
+	}
+	if g := d.tags["group"]; g != oldGroup {
+		c.ungroupDim(d, oldGroup)
+		if g != "" {
+			c.group[g] = append(c.group[g], d)
+		}
+	}
+}
+
+// Removes a dimension from the caches.
+func (c *Cube) removeDim(d *Dim) {
+	c.dims.Del(d.Id())
+	c.ungroupDim(d, d.tags["group"])
+}
+
+func (c *Cube) ungroupDim(d *Dim, group string) {
+	dims := c.group[group]
+	for i := range dims {
+		if dims[i].Id() == d.Id() {
+			c.group[group] = append(dims[:i], dims[i+1:]...)
+			break
+		}
+	}
+	if len(c.group[group]) == 0 {
+		delete(c.group, group)
+	}
+}
+
 func (c *Cube) canCoord(t reflect.Type) bool {
 	if t.Kind() == reflect.Slice {
 		return c.canCoord(t.Elem())
@@ -112,14 +184,14 @@ func (c *Cube) canCoord(t reflect.Type) bool {
 // Trasform the given object in coordinates.
 func (c *Cube) Coords(v interface{}) ([]Coord, error) {
 	if m, ok := v.(map[string]string); ok {
-		coord, err := c.coordMap(m)
+		coord, err := c.coordMap(context.Background(), m)
 		if err != nil {
 			return nil, err
 		}
 		return []Coord{coord}, nil
 	}
 	if m, ok := v.(map[string][]string); ok {
-		return c.coordsMap(m)
+		return c.coordsMap(context.Background(), m)
 	}
 	t := reflect.TypeOf(v)
 	r := reflect.ValueOf(v)
@@ -145,7 +217,7 @@ func (c *Cube) Coords(v interface{}) ([]Coord, error) {
 			c.trasform(f.Type, fv, m, key)
 		}
 	}
-	return c.coordsMap(m)
+	return c.coordsMap(context.Background(), m)
 }
 
 func (c *Cube) trasform(t reflect.Type, v reflect.Value, m map[string][]string, key string) {
@@ -160,12 +232,12 @@ func (c *Cube) trasform(t reflect.Type, v reflect.Value, m map[string][]string,
 	}
 }
 
-func (c *Cube) coordMap(smap map[string]string) (Coord, error) {
+func (c *Cube) coordMap(ctx context.Context, smap map[string]string) (Coord, error) {
 	coord := Coord{}
 	for _, dimId := range c.Data.Dimensions {
-		dm, err := c.dim(dimId)
+		dm, err := c.dim(ctx, dimId)
 		if err != nil {
-			return nil, fmt.Errorf("dimension %d missing: %s", dimId, err)
+			return nil, fmt.Errorf("dimension %d missing: %w", dimId, err)
 		}
 		elName, ok := smap[dm.Name()]
 		if !ok {
@@ -180,13 +252,13 @@ func (c *Cube) coordMap(smap map[string]string) (Coord, error) {
 	return coord, nil
 }
 
-func (c *Cube) coordsMap(smap map[string][]string) ([]Coord, error) {
+func (c *Cube) coordsMap(ctx context.Context, smap map[string][]string) ([]Coord, error) {
 	intMap := CoordArea{}
 
 	for _, dimId := range c.Data.Dimensions {
-		dm, err := c.dim(dimId)
+		dm, err := c.dim(ctx, dimId)
 		if err != nil {
-			return nil, fmt.Errorf("dimension %d missing: %s", dimId, err)
+			return nil, fmt.Errorf("dimension %d missing: %w", dimId, err)
 		}
 		v, ok := smap[dm.Name()]
 		if !ok {
@@ -208,9 +280,9 @@ func (c *Cube) coordsMap(smap map[string][]string) ([]Coord, error) {
 // Return the list of dimension names.
 func (c *Cube) DimNames() ([]string, error) {
 	if c.dims.Empty() {
-		err := c.init()
+		err := c.init(context.Background())
 		if err != nil {
-			return nil, fmt.Errorf("cannot get dim names")
+			return nil, fmt.Errorf("cannot get dim names: %w", err)
 		}
 	}
 	return c.dims.Names(), nil
@@ -218,19 +290,23 @@ func (c *Cube) DimNames() ([]string, error) {
 
 // Return a dimension by its name.
 func (c *Cube) Dim(name string) (*Dim, error) {
+	return c.dimByName(context.Background(), name)
+}
+
+func (c *Cube) dimByName(ctx context.Context, name string) (*Dim, error) {
 	if c.dims.Empty() {
-		err := c.init()
+		err := c.init(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get dim names")
+			return nil, fmt.Errorf("cannot get dim names: %w", err)
 		}
 	}
 	a := c.dims.Name(name)
 	if a == nil {
-		return nil, fmt.Errorf("dim %s does not exixts", name)
+		return nil, ErrMissDim(name)
 	}
 	d := a.(*Dim)
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -238,11 +314,11 @@ func (c *Cube) Dim(name string) (*Dim, error) {
 	return d, nil
 }
 
-func (c *Cube) dim(id int) (*Dim, error) {
+func (c *Cube) dim(ctx context.Context, id int) (*Dim, error) {
 	if c.dims.Empty() {
-		err := c.init()
+		err := c.init(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get dim id")
+			return nil, fmt.Errorf("cannot get dim id: %w", err)
 		}
 	}
 	a := c.dims.Id(id)
@@ -251,7 +327,7 @@ func (c *Cube) dim(id int) (*Dim, error) {
 	}
 	d := a.(*Dim)
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -260,14 +336,38 @@ func (c *Cube) dim(id int) (*Dim, error) {
 
 }
 
-func (c *Cube) analizeCoord(coord Coord) (bool, error) {
+// Return the element names of the given coordinates.
+func (c *Cube) CoordNames(coord Coord) ([]string, error) {
+	return c.coordNames(context.Background(), coord)
+}
+
+func (c *Cube) coordNames(ctx context.Context, coord Coord) ([]string, error) {
+	if len(c.Data.Dimensions) != len(coord) {
+		return nil, fmt.Errorf("wrong length %d, expected %d", len(coord), len(c.Data.Dimensions))
+	}
+	var names []string
+	for i, elId := range coord {
+		dm, err := c.dim(ctx, c.Data.Dimensions[i])
+		if err != nil {
+			return nil, err
+		}
+		el, err := dm.elem(ctx, elId)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, el.Name())
+	}
+	return names, nil
+}
+
+func (c *Cube) analizeCoord(ctx context.Context, coord Coord) (bool, error) {
 	if len(c.Data.Dimensions) != len(coord) {
 		return false, fmt.Errorf("wrong length %d, expected %d", len(coord), len(c.dims.objects))
 	}
 	var isConsolidate bool
 	for i, elId := range coord {
-		dm, _ := c.dim(c.Data.Dimensions[i])
-		el, err := dm.elem(elId)
+		dm, _ := c.dim(ctx, c.Data.Dimensions[i])
+		el, err := dm.elem(ctx, elId)
 		if err != nil {
 			return false, err
 		}
@@ -280,19 +380,18 @@ func (c *Cube) analizeCoord(coord Coord) (bool, error) {
 
 // Use the given coordinate to get a cellgroup formed by one cell.
 func (c *Cube) Cell(coord Coord) (*CellGroup, error) {
-	hasCons, err := c.analizeCoord(coord)
-	if err != nil {
-		return nil, err
-	}
-	cg := CellGroup{cube: c, hasCons: hasCons, cells: []Cell{Cell{Path: coord}}}
-	return &cg, nil
+	return c.cells(context.Background(), []Coord{coord})
 }
 
 // Use the given coordinates to get a cellgroup formed by more cells.
 func (c *Cube) Cells(coords []Coord) (*CellGroup, error) {
+	return c.cells(context.Background(), coords)
+}
+
+func (c *Cube) cells(ctx context.Context, coords []Coord) (*CellGroup, error) {
 	cg := CellGroup{cube: c}
 	for i := range coords {
-		hasCons, err := c.analizeCoord(coords[i])
+		hasCons, err := c.analizeCoord(ctx, coords[i])
 		if err != nil {
 			return nil, err
 		}
@@ -305,6 +404,7 @@ func (c *Cube) Cells(coords []Coord) (*CellGroup, error) {
 }
 
 // Use the given area to get a cellgroup formed by the cells in the area.
+// Every coordinate of the area is expanded, use ReadArea for large areas.
 func (c *Cube) CellGroup(coords CoordArea) (*CellGroup, error) {
 	cg, err := coords.Split(c.Data.Dimensions)
 	if err != nil {
@@ -313,6 +413,97 @@ func (c *Cube) CellGroup(coords CoordArea) (*CellGroup, error) {
 	return c.Cells(cg)
 }
 
+// Reloads the cube information from the server.
+func (c *Cube) Refresh(ctx context.Context) error {
+	rows, err := c.doRequest(ctx, "/cube/info", params{})
+	if err != nil {
+		return err
+	}
+	return c.update(rows)
+}
+
+// Updates the cube information using the first row of a response.
+func (c *Cube) update(rows []resultRow) error {
+	if len(rows) == 0 {
+		return internalErr("no cube row")
+	}
+	var cb Cube
+	if err := rows[0].Unmarshal(&cb); err != nil {
+		return err
+	}
+	c.Data, c.hash, c.tags = cb.Data, cb.hash, cb.tags
+	return nil
+}
+
+// Loads the cube in the server memory.
+func (c *Cube) Load(ctx context.Context) error {
+	return c.lifecycle(ctx, "/cube/load")
+}
+
+// Saves the cube to disk.
+func (c *Cube) Save(ctx context.Context) error {
+	return c.lifecycle(ctx, "/cube/save")
+}
+
+// Unloads the cube from the server memory.
+func (c *Cube) Unload(ctx context.Context) error {
+	return c.lifecycle(ctx, "/cube/unload")
+}
+
+func (c *Cube) lifecycle(ctx context.Context, url string) error {
+	_, err := c.doRequest(ctx, url, params{})
+	if err != nil {
+		return err
+	}
+	return c.Refresh(ctx)
+}
+
+// Changes the name of the cube.
+func (c *Cube) Rename(ctx context.Context, name string) error {
+	p := params{}
+	p.Add("new_name", name)
+	rows, err := c.doRequest(ctx, "/cube/rename", p)
+	if err != nil {
+		return err
+	}
+	return c.update(rows)
+}
+
+// Removes all the values of the cube.
+func (c *Cube) Clear(ctx context.Context) error {
+	p := params{}
+	p.Add("complete", "1")
+	rows, err := c.doRequest(ctx, "/cube/clear", p)
+	if err != nil {
+		return err
+	}
+	return c.update(rows)
+}
+
+// Removes the values of the cells in the given area.
+func (c *Cube) ClearArea(ctx context.Context, area CoordArea) error {
+	a, err := area.area(c.Data.Dimensions)
+	if err != nil {
+		return err
+	}
+	p := params{}
+	p.Set("area", a)
+	rows, pErr := c.doRequest(ctx, "/cube/clear", p)
+	if pErr != nil {
+		return pErr
+	}
+	return c.update(rows)
+}
+
+// Deletes the cube from the database, the object cannot be used afterwards.
+func (c *Cube) Destroy(ctx context.Context) error {
+	_, err := c.doRequest(ctx, "/cube/destroy", params{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *Cube) String() string {
 	return fmt.Sprintf("<cube id:%d name:%q dims:%d>", c.Data.Id, c.Data.Name, len(c.dims.Names()))
 }
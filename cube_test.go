@@ -0,0 +1,105 @@
+package cube
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A request received by the fake server.
+type fakeRequest struct {
+	Path  string
+	Query url.Values
+}
+
+// A fake Palo server with the database Db and the cube Cube of dimensions A (10) and B (11).
+// Responses are looked up by path and dimension, then by path only.
+type fakePalo struct {
+	mu       sync.Mutex
+	rows     map[string]string
+	requests []fakeRequest
+}
+
+func newFakePalo() *fakePalo {
+	return &fakePalo{rows: map[string]string{
+		"/server/login":          "SID;100;\n",
+		"/server/databases":      "1;\"Db\";2;1;1;0;0;\n",
+		"/database/cubes":        "5;\"Cube\";2;10,11;6;0;1;0;0;\n",
+		"/database/dimensions":   "10;\"A\";3;0;0;0;0;0;0;0;0;\n11;\"B\";2;0;0;0;0;0;0;0;0;\n",
+		"/dimension/elements?10": "1;\"a1\";0;0;0;0;1;0;;0;;;\n2;\"a2\";1;0;0;0;1;0;;0;;;\n3;\"a3\";2;0;0;0;1;0;;0;;;\n",
+		"/dimension/elements?11": "1;\"b1\";0;0;0;0;1;0;;0;;;\n2;\"b2\";1;0;0;0;1;0;;0;;;\n",
+		"/cube/clear":            "5;\"Cube\";2;10,11;6;0;1;0;0;\n",
+	}}
+}
+
+func (f *fakePalo) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if q, err = url.ParseQuery(string(b)); err != nil {
+			return nil, err
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, fakeRequest{req.URL.Path, q})
+	body, ok := f.rows[req.URL.Path+"?"+q.Get("dimension")]
+	if !ok {
+		body = f.rows[req.URL.Path]
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+// Return the queries received for the endpoint.
+func (f *fakePalo) queries(path string) []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var r []url.Values
+	for _, req := range f.requests {
+		if req.Path == path {
+			r = append(r, req.Query)
+		}
+	}
+	return r
+}
+
+// Opens the cube of a fake server.
+func testCube(t *testing.T, f *fakePalo) *Cube {
+	t.Helper()
+	conf := Config{User: "user", Pwd: "pwd", Host: "palo", Port: "7777", Db: "Db"}
+	c, err := Open(context.Background(), "Cube", conf, WithTransport(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClearArea(t *testing.T) {
+	f := newFakePalo()
+	c := testCube(t, f)
+	if err := c.ClearArea(context.Background(), CoordArea{10: {1, 2}, 11: {1}}); err != nil {
+		t.Fatal(err)
+	}
+	q := f.queries("/cube/clear")
+	if len(q) != 1 {
+		t.Fatalf("%d clear requests, expected 1", len(q))
+	}
+	if a := q[0].Get("area"); a != "1:2,1" {
+		t.Errorf("area %q, expected %q", a, "1:2,1")
+	}
+	if err := c.ClearArea(context.Background(), CoordArea{10: {1}}); err == nil {
+		t.Error("area without dimension 11 cleared")
+	}
+}
@@ -0,0 +1,223 @@
+package cube
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// A Palo database, cubes opened from it share the server session.
+type Database struct {
+	server *Server
+	Data   struct {
+		Id         int    // Identifier of the database
+		Name       string // Name of the database
+		Dimensions int    // Number of dimensions
+		Cubes      int    // Number of cubes
+		Status     int    // Status of database (0=unloaded, 1=loaded and 2=changed)
+		Type       int    // Type of database (0=normal, 1=system, 3=user info)
+		Token      int    // The database token of the database
+	}
+}
+
+func (db *Database) doRequest(ctx context.Context, url string, p params) (result []resultRow, pe *PaloError) {
+	if p == nil {
+		p = make(params)
+	}
+	p.Set("database", strconv.Itoa(db.Data.Id))
+	return db.server.client.doRequest(ctx, url, p)
+}
+
+// Return the database Id.
+func (db *Database) Id() int {
+	return db.Data.Id
+}
+
+// Return the database Name.
+func (db *Database) Name() string {
+	return db.Data.Name
+}
+
+// Return a cube of the database by its name.
+func (db *Database) Cube(ctx context.Context, name string) (*Cube, error) {
+	cb, err := db.getCube(ctx, name, false)
+	if err != nil {
+		return nil, err
+	}
+	err = cb.init(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cb, nil
+}
+
+// Return the dimensions of the database.
+func (db *Database) Dims(ctx context.Context) ([]*Dim, error) {
+	rows, err := db.doRequest(ctx, "/database/dimensions", nil)
+	if err != nil {
+		return nil, fmt.Errorf("dims: %w", err)
+	}
+	var dims []*Dim
+	for i := 0; i < len(rows); i++ {
+		var dm Dim
+		err := rows[i].Unmarshal(&dm)
+		if err != nil {
+			return nil, fmt.Errorf("dims: bad row %d (%w)", i, err)
+		}
+		dm.db = db
+		dims = append(dims, &dm)
+	}
+	return dims, nil
+}
+
+// Return a dimension of the database by its name.
+func (db *Database) Dim(ctx context.Context, name string) (*Dim, error) {
+	dims, err := db.Dims(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dims {
+		if d.Name() == name {
+			if err := d.init(ctx); err != nil {
+				return nil, err
+			}
+			return d, nil
+		}
+	}
+	return nil, ErrMissDim(name)
+}
+
+// Creates a new normal dimension with the given name.
+func (db *Database) CreateDim(ctx context.Context, name string) (*Dim, error) {
+	p := params{}
+	p.Add("new_name", name)
+	p.Add("type", "0")
+	rows, err := db.doRequest(ctx, "/dimension/create", p)
+	if err != nil {
+		return nil, err
+	}
+	d := Dim{db: db}
+	if err := d.update(rows); err != nil {
+		return nil, err
+	}
+	d.elems = newCache()
+	d.roots = newCache()
+	return &d, nil
+}
+
+// Creates a new cube with the given name, using the dimensions in the given order.
+func (db *Database) CreateCube(ctx context.Context, name string, dims ...string) (*Cube, error) {
+	all, err := db.Dims(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cube create: %w", err)
+	}
+	var ids = make(map[string]int)
+	for _, d := range all {
+		ids[d.Name()] = d.Id()
+	}
+	p := params{}
+	p.Add("new_name", name)
+	for _, n := range dims {
+		id, ok := ids[n]
+		if !ok {
+			return nil, ErrMissDim(n)
+		}
+		p.Add("dimensions", strconv.Itoa(id))
+	}
+	rows, pErr := db.doRequest(ctx, "/cube/create", p)
+	if pErr != nil {
+		return nil, pErr
+	}
+	cb := Cube{db: db}
+	if err := cb.update(rows); err != nil {
+		return nil, err
+	}
+	if err := cb.init(ctx); err != nil {
+		return nil, err
+	}
+	return &cb, nil
+}
+
+func (db *Database) getCube(ctx context.Context, cubeName string, isAttribute bool) (*Cube, error) {
+	p := params{}
+	if isAttribute {
+		p.Add("show_attribute", "1")
+	}
+	rows, err := db.doRequest(ctx, "/database/cubes", p)
+	if err != nil {
+		return nil, fmt.Errorf("cubes: %w", err)
+	}
+	var cb Cube
+	for i := 0; i < len(rows); i++ {
+		err := rows[i].Unmarshal(&cb)
+		if err != nil {
+			return nil, err
+		}
+		if cubeName == cb.Data.Name {
+			break
+		}
+	}
+	if cubeName != cb.Data.Name {
+		return nil, ErrMissCube(cubeName)
+	}
+	cb.isAttribute = isAttribute
+	cb.db = db
+	return &cb, nil
+}
+
+// Reloads the database information from the server.
+func (db *Database) Refresh(ctx context.Context) error {
+	rows, err := db.doRequest(ctx, "/database/info", nil)
+	if err != nil {
+		return err
+	}
+	return db.update(rows)
+}
+
+func (db *Database) update(rows []resultRow) error {
+	if len(rows) == 0 {
+		return internalErr("no database row")
+	}
+	s := db.server
+	if err := rows[0].Unmarshal(db); err != nil {
+		return err
+	}
+	db.server = s
+	return nil
+}
+
+// Loads the database in the server memory.
+func (db *Database) Load(ctx context.Context) error {
+	return db.lifecycle(ctx, "/database/load")
+}
+
+// Saves the database to disk.
+func (db *Database) Save(ctx context.Context) error {
+	return db.lifecycle(ctx, "/database/save")
+}
+
+// Unloads the database from the server memory.
+func (db *Database) Unload(ctx context.Context) error {
+	return db.lifecycle(ctx, "/database/unload")
+}
+
+func (db *Database) lifecycle(ctx context.Context, url string) error {
+	_, err := db.doRequest(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+	return db.Refresh(ctx)
+}
+
+// Deletes the database from the server, the object cannot be used afterwards.
+func (db *Database) Destroy(ctx context.Context) error {
+	_, err := db.doRequest(ctx, "/database/destroy", nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *Database) String() string {
+	return fmt.Sprintf("<database id:%d name:%q>", db.Data.Id, db.Data.Name)
+}
@@ -0,0 +1,317 @@
+package cube
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Errors returned in a *DecodeError by a strict Decoder.
+var (
+	ErrMissingColumn = errors.New("missing column")
+	ErrExtraColumn   = errors.New("column without field")
+)
+
+// An error in the decoding of a row.
+type DecodeError struct {
+	Field  string // Name of the struct field, empty for extra columns
+	Column int    // Index of the column, -1 if unknown
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("column %d: %s", e.Column, e.Err)
+	}
+	return fmt.Sprintf("field %s (column %d): %s", e.Field, e.Column, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// Decodes the columns of a row in the fields of a struct.
+// The palo tag of a field is the index of the column (palo:"2") or its name (palo:"name_cube"),
+// resolved using Columns. A field without tag uses the column after the one of the previous field
+// and palo:"-" skips the field. Nested structs are decoded starting from their column.
+//
+// Supported types are strings, booleans, integers, unsigned integers, floats, time.Time (unix
+// timestamp or RFC 3339), encoding.TextUnmarshaler implementations and slices of them (csv values).
+type Decoder struct {
+	Columns []string // Names of the columns, used by name tags
+	Strict  bool     // Fails if a field has no column or a column has no field
+}
+
+// Decodes a row in the struct pointed by v, using a non strict Decoder without column names.
+func Decode(row []string, v interface{}) error {
+	return (&Decoder{}).Decode(row, v)
+}
+
+// Decodes a row in the struct pointed by v.
+func (d *Decoder) Decode(row []string, v interface{}) error {
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Ptr || r.IsNil() || r.Elem().Kind() != reflect.Struct {
+		return errors.New("struct pointer needed")
+	}
+	var next int
+	var used = make([]bool, len(row))
+	if err := d.decodeStruct(row, r.Elem(), &next, used); err != nil {
+		return err
+	}
+	if d.Strict {
+		for i := range used {
+			if !used[i] {
+				return &DecodeError{Column: i, Err: ErrExtraColumn}
+			}
+		}
+	}
+	return nil
+}
+
+// Decodes rows in the slice of structs (or of struct pointers) pointed by v, using a non strict Decoder.
+func DecodeAll(rows [][]string, v interface{}) error {
+	return (&Decoder{}).DecodeAll(rows, v)
+}
+
+// Decodes rows in the slice of structs (or of struct pointers) pointed by v, appending them. Example:
+//
+//	var cubes []struct {
+//		Id   int    `palo:"cube"`
+//		Name string `palo:"name_cube"`
+//	}
+//	err := (&Decoder{Columns: []string{"cube", "name_cube"}}).DecodeAll(rows, &cubes)
+func (d *Decoder) DecodeAll(rows [][]string, v interface{}) error {
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Ptr || r.IsNil() || r.Elem().Kind() != reflect.Slice {
+		return errors.New("slice pointer needed")
+	}
+	sl := r.Elem()
+	et := sl.Type().Elem()
+	isPtr := et.Kind() == reflect.Ptr
+	if isPtr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return errors.New("slice of structs needed")
+	}
+	for i := range rows {
+		e := reflect.New(et)
+		if err := d.Decode(rows[i], e.Interface()); err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+		if !isPtr {
+			e = e.Elem()
+		}
+		sl = reflect.Append(sl, e)
+	}
+	r.Elem().Set(sl)
+	return nil
+}
+
+// Return the column index for a tag, next is used for empty tags.
+func (d *Decoder) column(tag string, next int) (int, error) {
+	if tag == "" {
+		return next, nil
+	}
+	if i, err := strconv.Atoi(tag); err == nil {
+		return i, nil
+	}
+	for i, c := range d.Columns {
+		if c == tag {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown column %q", tag)
+}
+
+func (d *Decoder) decodeStruct(row []string, v reflect.Value, next *int, used []bool) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("palo")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		col, err := d.column(tag, *next)
+		if err != nil {
+			if d.Strict {
+				return &DecodeError{Field: f.Name, Column: -1, Err: ErrMissingColumn}
+			}
+			continue
+		}
+		if isNested(f.Type) {
+			*next = col
+			if err := d.decodeStruct(row, v.Field(i), next, used); err != nil {
+				return err
+			}
+			continue
+		}
+		*next = col + 1
+		if col >= len(row) {
+			if d.Strict {
+				return &DecodeError{Field: f.Name, Column: col, Err: ErrMissingColumn}
+			}
+			continue
+		}
+		used[col] = true
+		if err := setValue(v.Field(i), row[col]); err != nil {
+			return &DecodeError{Field: f.Name, Column: col, Err: err}
+		}
+	}
+	return nil
+}
+
+// True for structs decoded field by field.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// Sets the value parsing the string.
+// Times are checked first, since they are also TextUnmarshalers.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == timeType {
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		if s == "" {
+			s = "0"
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseInt(v, s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseUint(v, s)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			s = "0"
+		}
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		values := resultField(s).Array()
+		sl := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i := range values {
+			if err := setValue(sl.Index(i), values[i]); err != nil {
+				return err
+			}
+		}
+		v.Set(sl)
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Parses an integer that fits in v, integral floats (1e3 or 2.0) are accepted and empty strings are 0.
+func parseInt(v reflect.Value, s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if errors.Is(err, strconv.ErrSyntax) {
+		var f float64
+		if f, err = parseIntegral(s); err != nil {
+			return 0, err
+		}
+		if f < math.MinInt64 || f >= -math.MinInt64 {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+		}
+		n = int64(f)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if v.OverflowInt(n) {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+	}
+	return n, nil
+}
+
+// Parses an unsigned integer that fits in v, integral floats (1e3 or 2.0) are accepted and empty strings are 0.
+func parseUint(v reflect.Value, s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if errors.Is(err, strconv.ErrSyntax) {
+		var f float64
+		if f, err = parseIntegral(s); err != nil {
+			return 0, err
+		}
+		if f < 0 || f >= 1<<64 {
+			return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+		}
+		n = uint64(f)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if v.OverflowUint(n) {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+	}
+	return n, nil
+}
+
+// Parses a float without fractional part.
+func parseIntegral(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: s, Err: errors.New("not an integer")}
+	}
+	return f, nil
+}
+
+// Parses a unix timestamp or a RFC 3339 time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
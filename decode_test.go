@@ -0,0 +1,173 @@
+package cube
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A TextUnmarshaler that keeps the upper case text.
+type upper string
+
+func (u *upper) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("empty text")
+	}
+	*u = upper(strings.ToUpper(string(b)))
+	return nil
+}
+
+type decodeIds struct {
+	Dims  []int
+	Names []upper
+}
+
+type decodeRow struct {
+	Id      int    `palo:"cube"`
+	Name    string `palo:"name_cube"`
+	Skip    string `palo:"-"`
+	Cells   int64
+	Ids     decodeIds
+	Loaded  bool      `palo:"6"`
+	Time    time.Time `palo:"7"`
+	Token   *uint16   `palo:"8"`
+	private int
+}
+
+var decodeColumns = []string{"cube", "name_cube", "cells", "dims", "names", "status", "loaded", "time", "token"}
+
+func TestDecode(t *testing.T) {
+	token := uint16(7)
+	for _, tc := range []struct {
+		name   string
+		row    []string
+		strict bool
+		exp    decodeRow
+		err    error
+	}{
+		{
+			name: "all columns",
+			row:  []string{"1", "Cube", "1e3", "10,11", "a,b", "x", "1", "1700000000", "7"},
+			exp: decodeRow{Id: 1, Name: "Cube", Cells: 1000, Ids: decodeIds{[]int{10, 11}, []upper{"A", "B"}},
+				Loaded: true, Time: time.Unix(1700000000, 0), Token: &token},
+		},
+		{
+			name: "rfc3339 and empty values",
+			row:  []string{"", "Cube", "", "", "", "", "", "2024-01-02T03:04:05Z", "7"},
+			exp: decodeRow{Name: "Cube", Ids: decodeIds{[]int{}, []upper{}},
+				Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Token: &token},
+		},
+		{
+			name: "missing columns",
+			row:  []string{"2", "Cube"},
+			exp:  decodeRow{Id: 2, Name: "Cube"},
+		},
+		{
+			name:   "strict missing column",
+			row:    []string{"2", "Cube"},
+			strict: true,
+			err:    ErrMissingColumn,
+		},
+		{
+			name:   "strict extra column",
+			row:    []string{"1", "Cube", "0", "", "a", "x", "0", "", "7", "extra"},
+			strict: true,
+			err:    ErrExtraColumn,
+		},
+		{
+			name: "bad text",
+			row:  []string{"1", "Cube", "0", "", "a,,b"},
+			err:  errors.New("empty text"),
+		},
+		{
+			name: "bad time",
+			row:  []string{"1", "Cube", "0", "", "", "", "0", "yesterday"},
+			err:  errors.New("cannot parse"),
+		},
+		{name: "int overflow", row: []string{"1e20"}, err: strconv.ErrRange},
+		{name: "int not integral", row: []string{"1.5"}, err: errors.New("not an integer")},
+		{name: "int syntax", row: []string{"one"}, err: strconv.ErrSyntax},
+		{name: "uint overflow", row: []string{"1", "Cube", "0", "", "", "", "0", "", "70000"}, err: strconv.ErrRange},
+		{name: "uint negative", row: []string{"1", "Cube", "0", "", "", "", "0", "", "-1"}, err: strconv.ErrRange},
+		{name: "bool syntax", row: []string{"1", "Cube", "0", "", "", "", "maybe"}, err: strconv.ErrSyntax},
+	} {
+		var r decodeRow
+		err := (&Decoder{Columns: decodeColumns, Strict: tc.strict}).Decode(tc.row, &r)
+		switch {
+		case tc.err == nil && err != nil:
+			t.Errorf("%s: %s", tc.name, err)
+		case tc.err == nil && !reflect.DeepEqual(r, tc.exp):
+			t.Errorf("%s: decoded %+v, expected %+v", tc.name, r, tc.exp)
+		case tc.err != nil && err == nil:
+			t.Errorf("%s: decoded %+v, expected %s", tc.name, r, tc.err)
+		case tc.err != nil && !errors.Is(err, tc.err) && !strings.Contains(err.Error(), tc.err.Error()):
+			t.Errorf("%s: got %s, expected %s", tc.name, err, tc.err)
+		}
+		var de *DecodeError
+		if err != nil && !errors.As(err, &de) {
+			t.Errorf("%s: %T is not a *DecodeError", tc.name, err)
+		}
+	}
+}
+
+func TestDecodeOverflow(t *testing.T) {
+	var r struct {
+		I8  int8
+		U8  uint8
+		I64 int64
+		U64 uint64
+	}
+	for _, tc := range []struct {
+		row []string
+		ok  bool
+	}{
+		{[]string{"127", "255", "-9223372036854775808", "18446744073709551615"}, true},
+		{[]string{"-128", "0", "9223372036854775807", "1e19"}, true},
+		{[]string{"300"}, false},
+		{[]string{"-129"}, false},
+		{[]string{"1e3"}, false},
+		{[]string{"0", "256"}, false},
+		{[]string{"0", "-1"}, false},
+		{[]string{"0", "0", "9223372036854775808"}, false},
+		{[]string{"0", "0", "1e20"}, false},
+		{[]string{"0", "0", "0", "18446744073709551616"}, false},
+		{[]string{"0", "0", "0", "2e19"}, false},
+		{[]string{"0", "0", "0", "Inf"}, false},
+		{[]string{"0", "0", "NaN"}, false},
+	} {
+		err := Decode(tc.row, &r)
+		if tc.ok && err != nil {
+			t.Errorf("%q: %s", tc.row, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%q: decoded %+v", tc.row, r)
+		}
+	}
+	if err := Decode([]string{"-128", "255", "", "1e19"}, &r); err != nil || r.I8 != -128 || r.U8 != 255 || r.I64 != 0 || r.U64 != 1e19 {
+		t.Errorf("decoded %+v (%v)", r, err)
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	var dst []*struct {
+		Id   int    `palo:"cube"`
+		Name string `palo:"name_cube"`
+	}
+	d := &Decoder{Columns: decodeColumns}
+	if err := d.DecodeAll([][]string{{"1", "A"}, {"2", "B"}}, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if len(dst) != 2 || dst[0].Id != 1 || dst[1].Name != "B" {
+		t.Errorf("decoded %+v", dst)
+	}
+	err := d.DecodeAll([][]string{{"1", "A"}, {"x", "B"}}, &dst)
+	if err == nil || !strings.HasPrefix(err.Error(), "row 1:") {
+		t.Errorf("got %v, expected a row 1 error", err)
+	}
+	if err := DecodeAll(nil, dst); err == nil {
+		t.Error("decoded in a slice value")
+	}
+}
@@ -1,23 +1,17 @@
 package cube
 
 import (
+	"context"
 	"fmt"
-	"net/url"
 	"strconv"
 	"strings"
 )
 
 const _LABEL = "label"
 
-// Error type for a non existing element.
-type ErrMissElem [2]string
-
-func (e ErrMissElem) Error() string {
-	return fmt.Sprintf("element %q missing in dimension %q", e[1], e[0])
-}
-
 // A Cube dimension.
 type Dim struct {
+	db    *Database
 	cube  *Cube
 	tags  map[string]string
 	elems cache
@@ -38,29 +32,34 @@ type Dim struct {
 	}
 }
 
-func (d *Dim) init() error {
-	err := d.initElems()
+func (d *Dim) doRequest(ctx context.Context, url string, p params) (result []resultRow, pe *PaloError) {
+	return d.db.doRequest(ctx, url, p)
+}
+
+func (d *Dim) init(ctx context.Context) error {
+	err := d.initElems(ctx)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (d *Dim) initElems() error {
+func (d *Dim) initElems(ctx context.Context) error {
 	d.elems = newCache()
 	d.roots = newCache()
 	p := make(params)
 	p.Add("dimension", strconv.Itoa(d.Data.Id))
-	rows, err := d.cube.doRequest("/dimension/elements", p)
+	rows, err := d.doRequest(ctx, "/dimension/elements", p)
 	if err != nil {
-		return fmt.Errorf("elems init: %s", err)
+		return fmt.Errorf("elems init: %w", err)
 	}
 	for i := 0; i < len(rows); i++ {
 		var el Elem
 		err := rows[i].Unmarshal(&el)
 		if err != nil {
-			return fmt.Errorf("elems init: bad row %d (%s)", i, err)
+			return fmt.Errorf("elems init: bad row %d (%w)", i, err)
 		}
+		el.dim = d
 		d.elems.Add(&el)
 	}
 	for _, key := range d.elems.Ids() {
@@ -76,6 +75,16 @@ func (d *Dim) initElems() error {
 	return nil
 }
 
+// Return the cached elements ordered by position.
+func (d *Dim) sortedElems() []*Elem {
+	var r []*Elem
+	for _, id := range d.elems.Ids() {
+		r = append(r, d.elems.Id(id).(*Elem))
+	}
+	sortElems(r)
+	return r
+}
+
 // Return the dimension Id.
 func (d *Dim) Id() int {
 	return d.Data.Id
@@ -89,7 +98,7 @@ func (d *Dim) Name() string {
 // Gives the elements name list.
 func (d *Dim) ElemNames() ([]string, error) {
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(context.Background())
 		if err != nil {
 			return nil, err
 		}
@@ -100,9 +109,9 @@ func (d *Dim) ElemNames() ([]string, error) {
 // Return an element by its name.
 func (d *Dim) Elem(name string) (*Elem, error) {
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(context.Background())
 		if err != nil {
-			return nil, fmt.Errorf("cannot get elems names")
+			return nil, fmt.Errorf("cannot get elems names: %w", err)
 		}
 	}
 	a := d.elems.Name(name)
@@ -112,11 +121,11 @@ func (d *Dim) Elem(name string) (*Elem, error) {
 	return a.(*Elem), nil
 }
 
-func (d *Dim) elem(id int) (*Elem, error) {
+func (d *Dim) elem(ctx context.Context, id int) (*Elem, error) {
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get elems id")
+			return nil, fmt.Errorf("cannot get elems id: %w", err)
 		}
 	}
 	a := d.elems.Id(id)
@@ -130,7 +139,7 @@ func (d *Dim) elem(id int) (*Elem, error) {
 // Gives the root elements name list.
 func (d *Dim) RootNames() ([]string, error) {
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(context.Background())
 		if err != nil {
 			return nil, err
 		}
@@ -140,8 +149,22 @@ func (d *Dim) RootNames() ([]string, error) {
 
 // Adds a new element to the dimension, a root if parent is empty.
 func (d *Dim) AddElem(name, parentName string, cons bool, label string) error {
+	return d.AddElemContext(context.Background(), name, parentName, cons, label)
+}
+
+// Adds a new element to the dimension, the requests are bound to the context.
+func (d *Dim) AddElemContext(ctx context.Context, name, parentName string, cons bool, label string) error {
+	t := ElemNumeric
+	if cons {
+		t = ElemConsolidated
+	}
+	return d.AddElemType(ctx, name, parentName, t, label)
+}
+
+// Adds a new element of the given type (ElemNumeric, ElemString or ElemConsolidated) to the dimension.
+func (d *Dim) AddElemType(ctx context.Context, name, parentName string, typ int, label string) error {
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(ctx)
 		if err != nil {
 			return err
 		}
@@ -156,13 +179,9 @@ func (d *Dim) AddElem(name, parentName string, cons bool, label string) error {
 	}
 	p := params{}
 	p.Add("dimension", strconv.Itoa(d.Data.Id))
-	t := "2"
-	if cons {
-		t = "4"
-	}
-	p.Add("type", t)
-	p.Add("new_name", url.QueryEscape(name))
-	row, pErr := d.cube.doRequest("/element/create", p)
+	p.Add("type", strconv.Itoa(typ))
+	p.Add("new_name", name)
+	row, pErr := d.doRequest(ctx, "/element/create", p)
 	if pErr != nil {
 		return pErr
 	}
@@ -176,55 +195,63 @@ func (d *Dim) AddElem(name, parentName string, cons bool, label string) error {
 		p.Add("dimension", strconv.Itoa(d.Data.Id))
 		p.Add("element", strconv.Itoa(parent.Id()))
 		p.Add("children", strconv.Itoa(el.Id()))
-		_, pErr := d.cube.doRequest("/element/append", p)
+		_, pErr := d.doRequest(ctx, "/element/append", p)
 		if pErr != nil {
 			return pErr
 		}
 	}
-	err = d.init()
+	err = d.init(ctx)
 	if err != nil {
 		return err
 	}
 	if label != "" {
-		return d.elemLabel(name, label)
+		return d.elemLabel(ctx, name, label)
 	}
 	return nil
 }
 
-func (d *Dim) elemLabel(name, label string) error {
+func (d *Dim) elemLabel(ctx context.Context, name, label string) error {
 	n := "#_" + d.Name()
-	cube, err := d.cube.client.GetCube(n, true)
+	cube, err := d.db.getCube(ctx, n, true)
 	if err != nil {
-		return fmt.Errorf("cannot get label cube: %s", err)
+		return fmt.Errorf("cannot get label cube: %w", err)
+	}
+	if err := cube.init(ctx); err != nil {
+		return fmt.Errorf("cannot get label cube: %w", err)
 	}
-	dim, err := cube.Dim(n)
+	dim, err := cube.dimByName(ctx, n)
 	if err != nil {
-		return fmt.Errorf("cannot get label dimension: %s", err)
+		return fmt.Errorf("cannot get label dimension: %w", err)
 	}
-	dim.AddElem(_LABEL, "", false, "")
+	dim.AddElemType(ctx, _LABEL, "", ElemString, "")
 
 	if _, err = dim.Elem(_LABEL); err != nil {
-		return fmt.Errorf("cannot create/find label element: %s", err)
+		return fmt.Errorf("cannot create/find label element: %w", err)
 	}
-	coord, err := cube.Coords(map[string]string{n: _LABEL, d.Name(): name})
+	coord, err := cube.coordMap(ctx, map[string]string{n: _LABEL, d.Name(): name})
 	if err != nil {
-		return fmt.Errorf("cannot get label coords: %s", err)
+		return fmt.Errorf("cannot get label coords: %w", err)
 	}
-	cell, err := cube.Cell(coord[0])
+	cell, err := cube.cells(ctx, []Coord{coord})
 	if err != nil {
-		return fmt.Errorf("cannot get label cell: %s", err)
+		return fmt.Errorf("cannot get label cell: %w", err)
 	}
-	err = cell.SetAll(label)
+	err = cell.SetAllContext(ctx, label)
 	if err != nil {
-		return fmt.Errorf("cannot set label value: %s", err)
+		return fmt.Errorf("cannot set label value: %w", err)
 	}
 	return nil
 }
 
 // Removes element from the dimension.
 func (d *Dim) DelElem(name string) error {
+	return d.DelElemContext(context.Background(), name)
+}
+
+// Removes element from the dimension, the requests are bound to the context.
+func (d *Dim) DelElemContext(ctx context.Context, name string) error {
 	if d.elems.Empty() {
-		err := d.init()
+		err := d.init(ctx)
 		if err != nil {
 			return err
 		}
@@ -236,7 +263,7 @@ func (d *Dim) DelElem(name string) error {
 	p := params{}
 	p.Add("dimension", strconv.Itoa(d.Data.Id))
 	p.Add("element", strconv.Itoa(el.Id()))
-	rows, pErr := d.cube.doRequest("/element/destroy", p)
+	rows, pErr := d.doRequest(ctx, "/element/destroy", p)
 	if pErr != nil {
 		return pErr
 	}
@@ -245,13 +272,75 @@ func (d *Dim) DelElem(name string) error {
 	if err != nil {
 		return err
 	}
-	err = d.init()
+	err = d.init(ctx)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// Updates the dimension information using the first row of a response.
+func (d *Dim) update(rows []resultRow) error {
+	if len(rows) == 0 {
+		return internalErr("no dimension row")
+	}
+	var dm Dim
+	if err := rows[0].Unmarshal(&dm); err != nil {
+		return err
+	}
+	d.Data, d.tags = dm.Data, dm.tags
+	return nil
+}
+
+// Changes the name of the dimension, tags included in the name are parsed again.
+func (d *Dim) Rename(ctx context.Context, name string) error {
+	p := params{}
+	p.Add("dimension", strconv.Itoa(d.Data.Id))
+	p.Add("new_name", name)
+	rows, pErr := d.doRequest(ctx, "/dimension/rename", p)
+	if pErr != nil {
+		return pErr
+	}
+	oldName, oldGroup := d.Name(), d.tags["group"]
+	if err := d.update(rows); err != nil {
+		return err
+	}
+	if d.cube != nil {
+		d.cube.renameDim(d, oldName, oldGroup)
+	}
+	return nil
+}
+
+// Removes all the elements of the dimension.
+func (d *Dim) Clear(ctx context.Context) error {
+	p := params{}
+	p.Add("dimension", strconv.Itoa(d.Data.Id))
+	rows, pErr := d.doRequest(ctx, "/dimension/clear", p)
+	if pErr != nil {
+		return pErr
+	}
+	if err := d.update(rows); err != nil {
+		return err
+	}
+	d.elems = newCache()
+	d.roots = newCache()
+	return nil
+}
+
+// Deletes the dimension from the database, the object cannot be used afterwards.
+func (d *Dim) Destroy(ctx context.Context) error {
+	p := params{}
+	p.Add("dimension", strconv.Itoa(d.Data.Id))
+	_, pErr := d.doRequest(ctx, "/dimension/destroy", p)
+	if pErr != nil {
+		return pErr
+	}
+	if d.cube != nil {
+		d.cube.removeDim(d)
+	}
+	return nil
+}
+
 func (d *Dim) String() string {
 	return fmt.Sprintf("<dim id:%d name:%q tags:%q elems:%d>", d.Data.Id, d.Data.Name, d.tags, d.elems.Size())
 }
@@ -1,26 +1,37 @@
 package cube
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Element types.
+const (
+	ElemNumeric      = 1
+	ElemString       = 2
+	ElemConsolidated = 4
 )
 
 // A dimension element.
 type Elem struct {
+	dim      *Dim
 	parents  []*Elem
 	children []*Elem
 	Data     struct {
-		Id              int    //Identifier of the element
-		Name            string //Name of the element
-		Position        int    //Position of the element
-		Level           int    //Level of the element
-		Indent          int    //Indent of the element
-		Depth           int    //Depth of the element
-		Type            int    //Type of the element (1=NUMERIC, 2=STRING, 4=CONSOLIDATED)
-		Number_parents  int    //Number of parents
-		Parents         []int  //Comma separate list of parent identifiers
-		Number_children int    //Number of children
-		Children        []int  //Comma separate list of children identifiers
-		Weights         []int  //Comma separate list of children weight
+		Id              int       //Identifier of the element
+		Name            string    //Name of the element
+		Position        int       //Position of the element
+		Level           int       //Level of the element
+		Indent          int       //Indent of the element
+		Depth           int       //Depth of the element
+		Type            int       //Type of the element (1=NUMERIC, 2=STRING, 4=CONSOLIDATED)
+		Number_parents  int       //Number of parents
+		Parents         []int     //Comma separate list of parent identifiers
+		Number_children int       //Number of children
+		Children        []int     //Comma separate list of children identifiers
+		Weights         []float64 //Comma separate list of children weight
 	}
 }
 
@@ -59,3 +70,153 @@ func (e *Elem) Children() []*Elem {
 func (e *Elem) newChild(el *Elem) {
 	e.children = append(e.children, el)
 }
+
+// Sorts the elements by position.
+func sortElems(elems []*Elem) {
+	sort.Slice(elems, func(i, j int) bool {
+		return elems[i].Data.Position < elems[j].Data.Position
+	})
+}
+
+func (e *Elem) params() params {
+	p := params{}
+	p.Add("dimension", strconv.Itoa(e.dim.Data.Id))
+	p.Add("element", strconv.Itoa(e.Data.Id))
+	return p
+}
+
+// Updates the element using the first row of a response and refreshes the cached links.
+func (e *Elem) update(rows []resultRow) error {
+	if len(rows) == 0 {
+		return internalErr("no element row")
+	}
+	var el Elem
+	if err := rows[0].Unmarshal(&el); err != nil {
+		return err
+	}
+	oldName := e.Data.Name
+	e.Data = el.Data
+	if oldName != e.Data.Name {
+		e.dim.elems.Rename(e, oldName)
+		if e.dim.roots.Id(e.Id()) != nil {
+			e.dim.roots.Rename(e, oldName)
+		}
+	}
+	return e.relink()
+}
+
+// Aligns the children links (and the parent links of the children) with Data.Children.
+func (e *Elem) relink() error {
+	var children []*Elem
+	for _, id := range e.Data.Children {
+		a := e.dim.elems.Id(id)
+		if a == nil {
+			return fmt.Errorf("elem with id %d does not exists in dimension %s", id, e.dim.Data.Name)
+		}
+		children = append(children, a.(*Elem))
+	}
+	for _, c := range e.children {
+		c.delParent(e)
+	}
+	for _, c := range children {
+		c.addParent(e)
+	}
+	e.children = children
+	e.Data.Number_children = len(children)
+	return nil
+}
+
+func (e *Elem) addParent(p *Elem) {
+	e.parents = append(e.parents, p)
+	e.Data.Parents = append(e.Data.Parents, p.Id())
+	e.Data.Number_parents = len(e.parents)
+	e.dim.roots.Del(e.Id())
+}
+
+func (e *Elem) delParent(p *Elem) {
+	for i := range e.parents {
+		if e.parents[i] == p {
+			e.parents = append(e.parents[:i:i], e.parents[i+1:]...)
+			break
+		}
+	}
+	for i, id := range e.Data.Parents {
+		if id == p.Id() {
+			e.Data.Parents = append(e.Data.Parents[:i:i], e.Data.Parents[i+1:]...)
+			break
+		}
+	}
+	e.Data.Number_parents = len(e.parents)
+	if len(e.parents) == 0 {
+		e.dim.roots.Add(e)
+	}
+}
+
+// Changes the name of the element.
+func (e *Elem) Rename(ctx context.Context, name string) error {
+	p := e.params()
+	p.Add("new_name", name)
+	rows, err := e.dim.doRequest(ctx, "/element/rename", p)
+	if err != nil {
+		return err
+	}
+	return e.update(rows)
+}
+
+// Moves the element to the given position in the dimension.
+func (e *Elem) Move(ctx context.Context, position int) error {
+	p := e.params()
+	p.Add("position", strconv.Itoa(position))
+	rows, err := e.dim.doRequest(ctx, "/element/move", p)
+	if err != nil {
+		return err
+	}
+	from := e.Data.Position
+	if err := e.update(rows); err != nil {
+		return err
+	}
+	to := e.Data.Position
+	for _, id := range e.dim.elems.Ids() {
+		el := e.dim.elems.Id(id).(*Elem)
+		switch pos := el.Data.Position; {
+		case el == e:
+		case from < to && pos > from && pos <= to:
+			el.Data.Position--
+		case to < from && pos >= to && pos < from:
+			el.Data.Position++
+		}
+	}
+	return nil
+}
+
+// Changes the element type and replaces its children with the given ones, using the weights.
+// Weights can be empty (all children weight 1) or have the same length of children.
+func (e *Elem) Replace(ctx context.Context, typ int, children []*Elem, weights []float64) error {
+	if l := len(weights); l != 0 && l != len(children) {
+		return fmt.Errorf("weights length %d, expected %d", l, len(children))
+	}
+	p := e.params()
+	p.Add("type", strconv.Itoa(typ))
+	if typ == ElemConsolidated {
+		for i, c := range children {
+			p.Add("children", strconv.Itoa(c.Id()))
+			if len(weights) != 0 {
+				p.Add("weights", strconv.FormatFloat(weights[i], 'f', -1, 64))
+			}
+		}
+	}
+	rows, err := e.dim.doRequest(ctx, "/element/replace", p)
+	if err != nil {
+		return err
+	}
+	return e.update(rows)
+}
+
+// Removes all the children of the element.
+func (e *Elem) RemoveChildren(ctx context.Context) error {
+	rows, err := e.dim.doRequest(ctx, "/element/remove_children", e.params())
+	if err != nil {
+		return err
+	}
+	return e.update(rows)
+}
@@ -0,0 +1,162 @@
+package cube
+
+import (
+	"fmt"
+)
+
+// An error in the execution of a request.
+// Use errors.Is with the error variables of this package to check the server error code.
+type PaloError struct {
+	Code      int // Code 0 means an internal package error
+	Name      string
+	Message   string
+	err       error
+	transient bool // network error or server failure, the request can be repeated
+}
+
+func (err *PaloError) Error() string {
+	if err == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s (%v): %s", err.Name, err.Code, err.Message)
+}
+
+// Return the cause of an internal error.
+func (err *PaloError) Unwrap() error {
+	if err == nil {
+		return nil
+	}
+	return err.err
+}
+
+// True if target is a *PaloError with the same server code, a nil error matches nothing.
+func (err *PaloError) Is(target error) bool {
+	t, ok := target.(*PaloError)
+	return ok && err != nil && t != nil && t.Code != 0 && t.Code == err.Code
+}
+
+// Error type for an unexpected HTTP status of a response.
+type StatusError int
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("http status %d", int(e))
+}
+
+func serverErr(code int, name string) *PaloError {
+	return &PaloError{Code: code, Name: name}
+}
+
+// Palo server errors.
+var (
+	ErrIdNotFound          = serverErr(1000, "ERROR_ID_NOT_FOUND")
+	ErrAuthFailed          = serverErr(1004, "ERROR_AUTHORIZATION_FAILED")
+	ErrInvalidType         = serverErr(1005, "ERROR_INVALID_TYPE")
+	ErrInvalidCoordinates  = serverErr(1006, "ERROR_INVALID_COORDINATES")
+	ErrConversionFailed    = serverErr(1007, "ERROR_CONVERSION_FAILED")
+	ErrNotAuthorized       = serverErr(1009, "ERROR_NOT_AUTHORIZED")
+	ErrInvalidPermission   = serverErr(1013, "ERROR_INVALID_PERMISSION")
+	ErrInvalidSession      = serverErr(1015, "ERROR_INVALID_SESSION")
+	ErrParameterMissing    = serverErr(1016, "ERROR_PARAMETER_MISSING")
+	ErrServerTokenOutdated = serverErr(1017, "ERROR_SERVER_TOKEN_OUTDATED")
+	ErrInvalidSplashMode   = serverErr(1018, "ERROR_INVALID_SPLASH_MODE")
+
+	ErrDatabaseNotFound       = serverErr(2000, "ERROR_DATABASE_NOT_FOUND")
+	ErrDatabaseNotLoaded      = serverErr(2001, "ERROR_DATABASE_NOT_LOADED")
+	ErrDatabaseUnsaved        = serverErr(2002, "ERROR_DATABASE_UNSAVED")
+	ErrDatabaseStillLoaded    = serverErr(2003, "ERROR_DATABASE_STILL_LOADED")
+	ErrInvalidDatabaseName    = serverErr(2004, "ERROR_INVALID_DATABASE_NAME")
+	ErrDatabaseNameInUse      = serverErr(2005, "ERROR_DATABASE_NAME_IN_USE")
+	ErrDatabaseUndeletable    = serverErr(2006, "ERROR_DATABASE_UNDELETABLE")
+	ErrDatabaseUnrenamable    = serverErr(2007, "ERROR_DATABASE_UNRENAMABLE")
+	ErrDatabaseTokenOutdated  = serverErr(2008, "ERROR_DATABASE_TOKEN_OUTDATED")
+	ErrDimensionEmpty         = serverErr(3000, "ERROR_DIMENSION_EMPTY")
+	ErrDimensionExists        = serverErr(3001, "ERROR_DIMENSION_EXISTS")
+	ErrDimensionNotFound      = serverErr(3002, "ERROR_DIMENSION_NOT_FOUND")
+	ErrInvalidDimensionName   = serverErr(3003, "ERROR_INVALID_DIMENSION_NAME")
+	ErrDimensionUnchangable   = serverErr(3004, "ERROR_DIMENSION_UNCHANGABLE")
+	ErrDimensionNameInUse     = serverErr(3005, "ERROR_DIMENSION_NAME_IN_USE")
+	ErrDimensionInUse         = serverErr(3006, "ERROR_DIMENSION_IN_USE")
+	ErrDimensionUndeletable   = serverErr(3007, "ERROR_DIMENSION_UNDELETABLE")
+	ErrDimensionUnrenamable   = serverErr(3008, "ERROR_DIMENSION_UNRENAMABLE")
+	ErrDimensionTokenOutdated = serverErr(3009, "ERROR_DIMENSION_TOKEN_OUTDATED")
+	ErrDimensionLocked        = serverErr(3010, "ERROR_DIMENSION_LOCKED")
+
+	ErrElementExists       = serverErr(4000, "ERROR_ELEMENT_EXISTS")
+	ErrCircularReference   = serverErr(4001, "ERROR_ELEMENT_CIRCULAR_REFERENCE")
+	ErrElementNameInUse    = serverErr(4002, "ERROR_ELEMENT_NAME_IN_USE")
+	ErrElementNameNotUniq  = serverErr(4003, "ERROR_ELEMENT_NAME_NOT_UNIQUE")
+	ErrElementNotFound     = serverErr(4004, "ERROR_ELEMENT_NOT_FOUND")
+	ErrElementNoChildOf    = serverErr(4005, "ERROR_ELEMENT_NO_CHILD_OF")
+	ErrInvalidElementName  = serverErr(4006, "ERROR_INVALID_ELEMENT_NAME")
+	ErrInvalidOffset       = serverErr(4007, "ERROR_INVALID_OFFSET")
+	ErrInvalidElementType  = serverErr(4008, "ERROR_INVALID_ELEMENT_TYPE")
+	ErrInvalidPosition     = serverErr(4009, "ERROR_INVALID_POSITION")
+	ErrElementUndeletable  = serverErr(4010, "ERROR_ELEMENT_NOT_DELETABLE")
+	ErrElementUnrenamable  = serverErr(4011, "ERROR_ELEMENT_NOT_RENAMABLE")
+	ErrElementUnchangeable = serverErr(4012, "ERROR_ELEMENT_NOT_CHANGABLE")
+
+	ErrCubeNotFound      = serverErr(5000, "ERROR_CUBE_NOT_FOUND")
+	ErrInvalidCubeName   = serverErr(5001, "ERROR_INVALID_CUBE_NAME")
+	ErrCubeNotLoaded     = serverErr(5002, "ERROR_CUBE_NOT_LOADED")
+	ErrCubeEmpty         = serverErr(5003, "ERROR_CUBE_EMPTY")
+	ErrCubeUnsaved       = serverErr(5004, "ERROR_CUBE_UNSAVED")
+	ErrSplashDisabled    = serverErr(5005, "ERROR_SPLASH_DISABLED")
+	ErrCubeNameInUse     = serverErr(5008, "ERROR_CUBE_NAME_IN_USE")
+	ErrCubeUndeletable   = serverErr(5009, "ERROR_CUBE_UNDELETABLE")
+	ErrCubeUnrenamable   = serverErr(5010, "ERROR_CUBE_UNRENAMABLE")
+	ErrCubeTokenOutdated = serverErr(5011, "ERROR_CUBE_TOKEN_OUTDATED")
+	ErrSplashNotPossible = serverErr(5012, "ERROR_SPLASH_NOT_POSSIBLE")
+	ErrLockNotFound      = serverErr(5013, "ERROR_CUBE_LOCK_NOT_FOUND")
+	ErrLockWrongUser     = serverErr(5014, "ERROR_CUBE_WRONG_USER")
+	ErrLockWrong         = serverErr(5015, "ERROR_CUBE_WRONG_LOCK")
+	ErrBlockedByLock     = serverErr(5016, "ERROR_CUBE_BLOCKED_BY_LOCK")
+	ErrLockNoCapacity    = serverErr(5017, "ERROR_CUBE_LOCK_NO_CAPACITY")
+)
+
+// Error type for a non existing database.
+type ErrMissDb string
+
+func (e ErrMissDb) Error() string {
+	return fmt.Sprintf("database %q missing", string(e))
+}
+
+// Matches ErrDatabaseNotFound.
+func (e ErrMissDb) Is(target error) bool {
+	return target == ErrDatabaseNotFound
+}
+
+// Error type for a non existing cube.
+type ErrMissCube string
+
+func (e ErrMissCube) Error() string {
+	return fmt.Sprintf("cube %q missing", string(e))
+}
+
+// Matches ErrCubeNotFound.
+func (e ErrMissCube) Is(target error) bool {
+	return target == ErrCubeNotFound
+}
+
+// Error type for a non existing dimension.
+type ErrMissDim string
+
+func (e ErrMissDim) Error() string {
+	return fmt.Sprintf("dimension %q missing", string(e))
+}
+
+// Matches ErrDimensionNotFound.
+func (e ErrMissDim) Is(target error) bool {
+	return target == ErrDimensionNotFound
+}
+
+// Error type for a non existing element.
+type ErrMissElem [2]string
+
+func (e ErrMissElem) Error() string {
+	return fmt.Sprintf("element %q missing in dimension %q", e[1], e[0])
+}
+
+// Matches ErrElementNotFound.
+func (e ErrMissElem) Is(target error) bool {
+	return target == ErrElementNotFound
+}
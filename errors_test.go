@@ -0,0 +1,70 @@
+package cube
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPaloErrorIs(t *testing.T) {
+	var err error = &PaloError{Code: ErrInvalidSession.Code, Name: ErrInvalidSession.Name}
+	if !errors.Is(err, ErrInvalidSession) {
+		t.Error("server error does not match its code")
+	}
+	if !errors.Is(fmt.Errorf("request: %w", err), ErrInvalidSession) {
+		t.Error("wrapped server error does not match its code")
+	}
+	if errors.Is(err, ErrIdNotFound) {
+		t.Error("server error matches another code")
+	}
+	if errors.Is(internalErr("internal"), &PaloError{}) {
+		t.Error("internal error matches code 0")
+	}
+	if !errors.Is(wrapErr("session", ErrClosed), ErrClosed) {
+		t.Error("internal error does not match its cause")
+	}
+}
+
+func TestPaloErrorNil(t *testing.T) {
+	var pe *PaloError
+	var err error = pe
+	if errors.Is(err, ErrInvalidSession) {
+		t.Error("nil error matches a server error")
+	}
+	if errors.Is(ErrInvalidSession, err) {
+		t.Error("server error matches a nil error")
+	}
+	if s := err.Error(); s != "<nil>" {
+		t.Errorf("nil error message %q", s)
+	}
+}
+
+func TestAggregateErrorsIs(t *testing.T) {
+	session := &PaloError{Code: ErrInvalidSession.Code, Name: ErrInvalidSession.Name}
+	err := cellsErr(10, []*ChunkErr{{Chunk: 1, First: 5, Last: 9, Err: session}}, nil)
+	if !errors.Is(err, ErrInvalidSession) {
+		t.Error("cells error does not match the chunk error")
+	}
+	if errors.Is(err, ErrIdNotFound) {
+		t.Error("cells error matches another code")
+	}
+	var ce *ChunkErr
+	if !errors.As(err, &ce) || ce.Chunk != 1 {
+		t.Error("cells error does not contain the chunk error")
+	}
+	inUse := &PaloError{Code: ErrElementNameInUse.Code, Name: ErrElementNameInUse.Name}
+	err = &ElemsErr{ErrorMap: map[int]error{
+		0: fmt.Errorf("empty name"),
+		3: inUse,
+		4: &ErrMissElem{"Dim", "Parent"},
+	}}
+	if !errors.Is(err, ErrElementNameInUse) {
+		t.Error("elems error does not match the element error")
+	}
+	if !errors.Is(err, ErrElementNotFound) {
+		t.Error("elems error does not match the missing parent")
+	}
+	if errors.Is(err, ErrInvalidSession) {
+		t.Error("elems error matches another code")
+	}
+}
@@ -0,0 +1,165 @@
+package cube
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const defaultBlockSize = 1000
+
+// Options for reading the cells of an area.
+type AreaOptions struct {
+	BlockSize int  // Number of cells fetched by each request, 1000 if 0
+	SkipEmpty bool // Skips the cells without a value
+	BaseOnly  bool // Skips the consolidated cells
+}
+
+// Reads cells from the server block by block. Example:
+//
+//	r, err := cube.ReadArea(ctx, area, AreaOptions{SkipEmpty: true})
+//	for r.Next() {
+//		c := r.Cell()
+//	}
+//	err = r.Err()
+type CellReader struct {
+	ctx   context.Context
+	cube  *Cube
+	p     params
+	size  int
+	skip  bool
+	block []Cell
+	cell  Cell
+	last  Coord
+	done  bool
+	err   error
+}
+
+// Options for exporting the cells of a cube.
+type ExportOptions struct {
+	AreaOptions
+	Area      CoordArea // Area to export, the whole cube if nil
+	Condition string    // Value condition of the exported cells, for instance ">= 100 and < 200"
+	From      Coord     // Checkpoint of a previous export, the export restarts after it
+}
+
+// Return a reader for the cells of the given area, fetched using /cell/export.
+func (c *Cube) ReadArea(ctx context.Context, area CoordArea, opts AreaOptions) (*CellReader, error) {
+	return c.Export(ctx, ExportOptions{AreaOptions: opts, Area: area})
+}
+
+// Return a reader for the cells of the cube that match the options, fetched using /cell/export.
+// Use BaseOnly and SkipEmpty to get the filled base cells only.
+func (c *Cube) Export(ctx context.Context, opts ExportOptions) (*CellReader, error) {
+	area := opts.Area
+	if area == nil {
+		area = CoordArea{}
+		for _, dimId := range c.Data.Dimensions {
+			dm, err := c.dim(ctx, dimId)
+			if err != nil {
+				return nil, fmt.Errorf("dimension %d missing: %w", dimId, err)
+			}
+			for _, el := range dm.sortedElems() {
+				area[dimId] = append(area[dimId], el.Id())
+			}
+		}
+	}
+	a, err := area.area(c.Data.Dimensions)
+	if err != nil {
+		return nil, err
+	}
+	r := CellReader{ctx: ctx, cube: c, p: params{}, size: opts.BlockSize, skip: opts.SkipEmpty, last: opts.From}
+	if r.size <= 0 {
+		r.size = defaultBlockSize
+	}
+	r.p.Set("area", a)
+	r.p.Set("blocksize", strconv.Itoa(r.size))
+	r.p.Set("skip_empty", boolParam(opts.SkipEmpty))
+	r.p.Set("base_only", boolParam(opts.BaseOnly))
+	if opts.Condition != "" {
+		r.p.Set("condition", opts.Condition)
+	}
+	return &r, nil
+}
+
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// Moves to the next cell, fetching a new block if needed. Returns false at the end or on error.
+func (r *CellReader) Next() bool {
+	for len(r.block) == 0 {
+		if r.done || r.err != nil {
+			return false
+		}
+		r.err = r.fetch()
+	}
+	r.cell, r.block = r.block[0], r.block[1:]
+	return true
+}
+
+// Return the current cell.
+func (r *CellReader) Cell() Cell {
+	return r.cell
+}
+
+// Return the element names of the current cell coordinates.
+func (r *CellReader) Names() ([]string, error) {
+	return r.cube.coordNames(r.ctx, r.cell.Path)
+}
+
+// Return the coordinates of the current cell, use them as ExportOptions.From to resume the export.
+func (r *CellReader) Checkpoint() Coord {
+	return r.cell.Path
+}
+
+// Return the error that stopped the reader, if any.
+func (r *CellReader) Err() error {
+	return r.err
+}
+
+// Fetches the block after the last cell read.
+func (r *CellReader) fetch() error {
+	p := params{}
+	for k, v := range r.p {
+		p[k] = v
+	}
+	if r.last != nil {
+		p.Set("path", r.last.String())
+	}
+	rows, pErr := r.cube.doRequest(r.ctx, "/cell/export", p)
+	if pErr != nil {
+		return fmt.Errorf("export: %w", pErr)
+	}
+	var n int
+	for i := range rows {
+		if len(rows[i]) < 4 {
+			// progress row: exported cells;total cells
+			if len(rows[i]) == 2 && rows[i][0].String() == rows[i][1].String() {
+				r.done = true
+			}
+			continue
+		}
+		var c Cell
+		if err := c.unmarshal(rows[i]); err != nil {
+			return fmt.Errorf("export: bad row %d (%w)", i, err)
+		}
+		path, err := parseCoord(rows[i][3].String())
+		if err != nil {
+			return fmt.Errorf("export: bad row %d (%w)", i, err)
+		}
+		c.Path, r.last = path, path
+		n++
+		if r.skip && c.Data.Exist == 0 {
+			continue
+		}
+		r.block = append(r.block, c)
+	}
+	if n < r.size {
+		r.done = true
+	}
+	return nil
+}
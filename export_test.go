@@ -0,0 +1,77 @@
+package cube
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadArea(t *testing.T) {
+	f := newFakePalo()
+	f.rows["/cell/export"] = "1;1;5;1,2;\n2;1;\"x\";3,2;\n2;2;\n"
+	c := testCube(t, f)
+	r, err := c.ReadArea(context.Background(), CoordArea{10: {1, 3}, 11: {2}}, AreaOptions{BlockSize: 10, SkipEmpty: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cells []Cell
+	for r.Next() {
+		cells = append(cells, r.Cell())
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 2 || cells[0].Float() != 5 || cells[1].Str() != "x" || cells[1].Path.String() != "3,2" {
+		t.Errorf("cells %+v", cells)
+	}
+	q := f.queries("/cell/export")
+	if len(q) != 1 {
+		t.Fatalf("%d export requests, expected 1", len(q))
+	}
+	for k, v := range map[string]string{"area": "1:3,2", "blocksize": "10", "skip_empty": "1", "base_only": "0", "path": ""} {
+		if a := q[0].Get(k); a != v {
+			t.Errorf("%s %q, expected %q", k, a, v)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	f := newFakePalo()
+	f.rows["/cell/export"] = "1;1;5;3,1;\n1;0;;3,2;\n6;6;\n"
+	c := testCube(t, f)
+	for _, tc := range []struct {
+		opts ExportOptions
+		path string
+	}{
+		{ExportOptions{Condition: ">= 5"}, ""},
+		{ExportOptions{From: Coord{2, 1}}, "2,1"},
+	} {
+		r, err := c.Export(context.Background(), tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		for r.Next() {
+			n++
+		}
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 || r.Checkpoint().String() != "3,2" {
+			t.Errorf("%d cells read, checkpoint %v", n, r.Checkpoint())
+		}
+	}
+	q := f.queries("/cell/export")
+	if len(q) != 2 {
+		t.Fatalf("%d export requests, expected 2", len(q))
+	}
+	for i, ex := range []map[string]string{
+		{"area": "1:2:3,1:2", "path": "", "condition": ">= 5"},
+		{"area": "1:2:3,1:2", "path": "2,1", "condition": ""},
+	} {
+		for k, v := range ex {
+			if a := q[i].Get(k); a != v {
+				t.Errorf("request %d: %s %q, expected %q", i, k, a, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package cube
+
+import (
+	"context"
+)
+
+// Collects the elements reachable with next, up to the given depth (unlimited if depth <= 0).
+func walkElems(start []*Elem, next func(*Elem) []*Elem, depth int) []*Elem {
+	var r []*Elem
+	var seen = make(map[*Elem]bool)
+	for level := 1; len(start) > 0 && (depth <= 0 || level <= depth); level++ {
+		var found []*Elem
+		for _, e := range start {
+			for _, n := range next(e) {
+				if seen[n] {
+					continue
+				}
+				seen[n] = true
+				found = append(found, n)
+			}
+		}
+		r = append(r, found...)
+		start = found
+	}
+	sortElems(r)
+	return r
+}
+
+func parentsOf(e *Elem) []*Elem  { return e.parents }
+func childrenOf(e *Elem) []*Elem { return e.children }
+
+// Return all the ancestors of the element, ordered by position.
+func (e *Elem) Ancestors() []*Elem {
+	return walkElems([]*Elem{e}, parentsOf, 0)
+}
+
+// Return the descendants of the element up to the given depth (all if depth <= 0), ordered by position.
+func (e *Elem) Descendants(depth int) []*Elem {
+	return walkElems([]*Elem{e}, childrenOf, depth)
+}
+
+// Return the base elements under the element, or the element itself if it has no children.
+func (e *Elem) Leaves() []*Elem {
+	if len(e.children) == 0 {
+		return []*Elem{e}
+	}
+	var r []*Elem
+	for _, d := range e.Descendants(0) {
+		if len(d.children) == 0 {
+			r = append(r, d)
+		}
+	}
+	return r
+}
+
+// Return the other children of the element parents (the other roots for a root), ordered by position.
+func (e *Elem) Siblings() []*Elem {
+	var r []*Elem
+	var seen = map[*Elem]bool{e: true}
+	if len(e.parents) == 0 && e.dim != nil {
+		for _, id := range e.dim.roots.Ids() {
+			s := e.dim.roots.Id(id).(*Elem)
+			if !seen[s] {
+				seen[s] = true
+				r = append(r, s)
+			}
+		}
+	}
+	for _, p := range e.parents {
+		for _, s := range p.children {
+			if !seen[s] {
+				seen[s] = true
+				r = append(r, s)
+			}
+		}
+	}
+	sortElems(r)
+	return r
+}
+
+// True if the element is an ancestor of the given one.
+func (e *Elem) IsAncestorOf(o *Elem) bool {
+	for _, a := range o.Ancestors() {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the path from the element to a root, following the first parent of each element.
+func (e *Elem) PathToRoot() []*Elem {
+	var r = []*Elem{e}
+	var seen = map[*Elem]bool{e: true}
+	for el := e; len(el.parents) > 0 && !seen[el.parents[0]]; {
+		el = el.parents[0]
+		seen[el] = true
+		r = append(r, el)
+	}
+	return r
+}
+
+// Return the base elements of the dimension, ordered by position.
+func (d *Dim) Leaves() ([]*Elem, error) {
+	return d.filterElems(func(e *Elem) bool { return len(e.children) == 0 })
+}
+
+// Return the elements with the given level (0 for base elements), ordered by position.
+// The level of a consolidated element is one more than the highest level of its children.
+func (d *Dim) ElemsAtLevel(level int) ([]*Elem, error) {
+	var levels = make(map[*Elem]int)
+	var levelOf func(e *Elem) int
+	levelOf = func(e *Elem) int {
+		if l, ok := levels[e]; ok {
+			return l
+		}
+		levels[e] = 0
+		var l int
+		for _, c := range e.children {
+			if cl := levelOf(c) + 1; cl > l {
+				l = cl
+			}
+		}
+		levels[e] = l
+		return l
+	}
+	return d.filterElems(func(e *Elem) bool { return levelOf(e) == level })
+}
+
+// Return the elements with the given depth (0 for root elements), ordered by position.
+// The depth of an element is one more than the highest depth of its parents.
+func (d *Dim) ElemsAtDepth(depth int) ([]*Elem, error) {
+	var depths = make(map[*Elem]int)
+	var depthOf func(e *Elem) int
+	depthOf = func(e *Elem) int {
+		if l, ok := depths[e]; ok {
+			return l
+		}
+		depths[e] = 0
+		var l int
+		for _, p := range e.parents {
+			if pl := depthOf(p) + 1; pl > l {
+				l = pl
+			}
+		}
+		depths[e] = l
+		return l
+	}
+	return d.filterElems(func(e *Elem) bool { return depthOf(e) == depth })
+}
+
+func (d *Dim) filterElems(f func(*Elem) bool) ([]*Elem, error) {
+	if d.elems.Empty() {
+		err := d.init(context.Background())
+		if err != nil {
+			return nil, err
+		}
+	}
+	var r []*Elem
+	for _, e := range d.sortedElems() {
+		if f(e) {
+			r = append(r, e)
+		}
+	}
+	return r, nil
+}
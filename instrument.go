@@ -0,0 +1,100 @@
+package cube
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The description of a request sent to the server.
+type RequestInfo struct {
+	Endpoint      string        // Path of the endpoint, for instance /cell/values
+	Method        string        // HTTP method
+	Database      string        // Identifier of the database, empty if not used
+	Cube          string        // Identifier of the cube, empty if not used
+	RequestBytes  int           // Size of the encoded parameters
+	ResponseBytes int           // Size of the response body
+	Rows          int           // Number of rows of the response
+	Status        int           // HTTP status, 0 if the request failed before the response
+	Duration      time.Duration // Time from the request to the end of the response
+	Err           error         // Error of the request, nil on success
+}
+
+func newRequestInfo(url, method string, p params, size int) *RequestInfo {
+	info := RequestInfo{Endpoint: url, Method: method, RequestBytes: size}
+	if v := p["database"].data; len(v) > 0 {
+		info.Database = v[0]
+	}
+	if v := p["cube"].data; len(v) > 0 {
+		info.Cube = v[0]
+	}
+	return &info
+}
+
+// Receives every HTTP request sent by the client (retries included), to collect metrics or traces.
+// Start can return a new context (for instance carrying a span), used for the request and passed to End.
+type Instrument interface {
+	Start(ctx context.Context, info *RequestInfo) context.Context
+	End(ctx context.Context, info *RequestInfo)
+}
+
+// Calls i around every request.
+func WithInstrument(i Instrument) Option {
+	return func(c *client) {
+		c.instrument = i
+	}
+}
+
+// An Instrument that keeps the requests in memory, useful in tests.
+type MemoryInstrument struct {
+	mu       sync.Mutex
+	requests []RequestInfo
+}
+
+// Does nothing, the request is recorded by End.
+func (m *MemoryInstrument) Start(ctx context.Context, info *RequestInfo) context.Context {
+	return ctx
+}
+
+// Records the request.
+func (m *MemoryInstrument) End(ctx context.Context, info *RequestInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, *info)
+}
+
+// Return the recorded requests, in order of completion.
+func (m *MemoryInstrument) Requests() []RequestInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RequestInfo(nil), m.requests...)
+}
+
+// Return the recorded requests to the endpoint.
+func (m *MemoryInstrument) Endpoint(url string) []RequestInfo {
+	var r []RequestInfo
+	for _, info := range m.Requests() {
+		if info.Endpoint == url {
+			r = append(r, info)
+		}
+	}
+	return r
+}
+
+// Return the number of recorded requests that failed.
+func (m *MemoryInstrument) Errors() int {
+	var n int
+	for _, info := range m.Requests() {
+		if info.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Removes the recorded requests.
+func (m *MemoryInstrument) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = nil
+}
@@ -0,0 +1,175 @@
+package cube
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The severity of a log entry.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// A key value pair of a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// A structured logger. Credentials and session ids are redacted before reaching it.
+type Logger interface {
+	Log(level Level, msg string, fields ...Field)
+}
+
+const defaultBodyLimit = 512
+
+// Sends the log entries of the client to l.
+func WithLogger(l Logger) Option {
+	return func(c *client) {
+		c.logger = l
+	}
+}
+
+// Sets the maximum number of bytes of a response body included in the logs, 0 omits the body.
+func WithBodyLimit(n int) Option {
+	return func(c *client) {
+		c.bodyLimit = n
+	}
+}
+
+// Writes the requests and the responses to w, as text lines of a debug level Logger.
+func WithWriter(w io.Writer) Option {
+	return func(c *client) {
+		if w != nil {
+			c.logger = WriterLogger(w, LevelDebug)
+		}
+	}
+}
+
+// Return a Logger that writes the entries with at least the given level to w, one per line.
+func WriterLogger(w io.Writer, min Level) Logger {
+	return &writerLogger{w: w, min: min}
+}
+
+type writerLogger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	min Level
+}
+
+func (l *writerLogger) Log(level Level, msg string, fields ...Field) {
+	if level < l.min {
+		return
+	}
+	b := bytes.NewBuffer(nil)
+	fmt.Fprintf(b, "%s %s %s", time.Now().Format(time.RFC3339), level, msg)
+	for _, f := range fields {
+		fmt.Fprintf(b, " %s=%q", f.Key, fmt.Sprint(f.Value))
+	}
+	b.WriteByte('\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(b.Bytes())
+}
+
+// Parameters and endpoints whose values are never logged.
+var (
+	secretParams    = map[string]bool{"sid": true, "password": true, "new_password": true}
+	secretResponses = map[string]bool{"/server/login": true}
+)
+
+const redacted = "[REDACTED]"
+
+// Returns the unescaped parameters with the secret values redacted.
+func (p params) redacted() string {
+	var keys []string
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var s []string
+	for _, k := range keys {
+		v := strings.Join(p[k].data, p[k].joiner)
+		if secretParams[k] {
+			v = redacted
+		}
+		s = append(s, k+"="+v)
+	}
+	return strings.Join(s, "&")
+}
+
+// Keeps the first bytes written to it, counting all of them.
+type bodyBuffer struct {
+	limit int
+	size  int
+	data  []byte
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	if n := b.limit - len(b.data); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		b.data = append(b.data, p[:n]...)
+	}
+	b.size += len(p)
+	return len(p), nil
+}
+
+// Logs a completed request.
+func (c *client) logRequest(url, method string, p params, status int, d time.Duration, rows int, body *bodyBuffer, pe *PaloError) {
+	if c.logger == nil {
+		return
+	}
+	fields := []Field{
+		{"endpoint", url},
+		{"method", method},
+		{"params", p.redacted()},
+		{"status", status},
+		{"duration", d},
+		{"rows", rows},
+	}
+	if c.bodyLimit > 0 {
+		b := string(body.data)
+		switch {
+		case secretResponses[url]:
+			b = redacted
+		case body.size > len(body.data):
+			b = fmt.Sprintf("%s...(%d bytes)", b, body.size)
+		}
+		fields = append(fields, Field{"body", b})
+	}
+	if pe != nil {
+		level := LevelWarn
+		if pe.Code == 0 {
+			level = LevelError
+		}
+		c.logger.Log(level, "request failed", append(fields, Field{"error", pe})...)
+		return
+	}
+	c.logger.Log(LevelDebug, "request", fields...)
+}
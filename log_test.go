@@ -0,0 +1,82 @@
+package cube
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	testSid = "SECRETSID"
+	testPwd = "SECRETPWD"
+)
+
+// A transport that accepts the login and answers the other requests with fn.
+type loginTransport func(req *http.Request) (*http.Response, error)
+
+func (fn loginTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/server/login" {
+		return response(req, 200, testSid+";100;\n"), nil
+	}
+	return fn(req)
+}
+
+func response(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}
+}
+
+func TestLogRedactedErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rt   loginTransport
+	}{
+		{"closed connection", func(req *http.Request) (*http.Response, error) {
+			return nil, io.EOF
+		}},
+		{"server error", func(req *http.Request) (*http.Response, error) {
+			return response(req, 500, "internal error"), nil
+		}},
+		{"palo error", func(req *http.Request) (*http.Response, error) {
+			return response(req, 400, "1015;\"ERROR_INVALID_SESSION\";\"invalid session\";\n"), nil
+		}},
+		{"not found", func(req *http.Request) (*http.Response, error) {
+			return response(req, 404, "<html>not found</html>"), nil
+		}},
+		{"bad row", func(req *http.Request) (*http.Response, error) {
+			return response(req, 200, "\"open;\n"), nil
+		}},
+	} {
+		var buf bytes.Buffer
+		conf := Config{User: "user", Pwd: testPwd, Host: "palo", Port: "7777"}
+		s, err := Connect(context.Background(), conf,
+			WithTransport(tc.rt),
+			WithLogger(WriterLogger(&buf, LevelDebug)),
+		)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		_, err = s.Do(context.Background(), "/server/databases", nil)
+		if err == nil {
+			t.Errorf("%s: no error", tc.name)
+		}
+		for _, secret := range []string{testSid, testPwd} {
+			if strings.Contains(err.Error(), secret) {
+				t.Errorf("%s: error contains %s: %s", tc.name, secret, err)
+			}
+			if strings.Contains(buf.String(), secret) {
+				t.Errorf("%s: log contains %s:\n%s", tc.name, secret, buf.String())
+			}
+		}
+		if !strings.Contains(buf.String(), "request failed") {
+			t.Errorf("%s: failure not logged:\n%s", tc.name, buf.String())
+		}
+	}
+}
@@ -1,7 +1,8 @@
 package cube
 
 import (
-	"fmt"
+	"net/url"
+	"sort"
 	"strings"
 )
 
@@ -27,6 +28,18 @@ func (p *params) Add(k string, v ...string) {
 	p.append(k, v...)
 }
 
+// Adds values to a csv list, quoting the ones that contain separators or quotes.
+func (p *params) List(k string, v ...string) {
+	var values []string
+	for _, s := range v {
+		if strings.ContainsAny(s, `,:;"`) {
+			s = quote(s)
+		}
+		values = append(values, s)
+	}
+	p.Add(k, values...)
+}
+
 func (p *params) Set(k string, v string) {
 	(*p)[k] = par{data: []string{v}}
 }
@@ -42,10 +55,22 @@ func (p *params) Path(k string, v ...[]string) {
 	p.append(k, values...)
 }
 
+// Returns the encoded querystring, with keys in alphabetical order.
 func (p params) String() string {
+	var keys []string
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 	var s []string
-	for k, v := range p {
-		s = append(s, fmt.Sprintf("%s=%s", k, strings.Join(v.data, v.joiner)))
+	for _, k := range keys {
+		v := p[k]
+		s = append(s, url.QueryEscape(k)+"="+url.QueryEscape(strings.Join(v.data, v.joiner)))
 	}
 	return strings.Join(s, "&")
 }
+
+// Quotes a value using the Palo csv format.
+func quote(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
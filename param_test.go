@@ -0,0 +1,124 @@
+package cube
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Element names that need escaping in a querystring or quoting in the Palo csv format.
+var nastyNames = []string{
+	"plain",
+	"a&b",
+	"a+b",
+	"#hash",
+	"key=value",
+	"with space",
+	" leading and trailing ",
+	"comma,name",
+	"colon:name",
+	"semi;colon",
+	`quo"te`,
+	`"quoted"`,
+	`""`,
+	`,:;"`,
+	"new\nline",
+	"carriage\r\nreturn",
+	"100%",
+	"città",
+	"日本語",
+	"emoji 🎲",
+	"tab\there",
+}
+
+// Reverts quote.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	return strings.Replace(s[1:len(s)-1], `""`, `"`, -1)
+}
+
+func TestParamsString(t *testing.T) {
+	for _, name := range nastyNames {
+		p := params{}
+		p.Set("name_element", name)
+		p.Add("dimension", "1")
+		q, err := url.ParseQuery(p.String())
+		if err != nil {
+			t.Errorf("%q: %s", name, err)
+			continue
+		}
+		if v := q.Get("name_element"); v != name {
+			t.Errorf("%q: decoded as %q", name, v)
+		}
+		if v := q.Get("dimension"); v != "1" {
+			t.Errorf("%q: dimension decoded as %q", name, v)
+		}
+		if len(q) != 2 {
+			t.Errorf("%q: %d keys decoded, expected 2", name, len(q))
+		}
+	}
+}
+
+func TestParamsList(t *testing.T) {
+	for _, name := range nastyNames {
+		p := params{}
+		p.List("name_elements", name)
+		q, err := url.ParseQuery(p.String())
+		if err != nil {
+			t.Errorf("%q: %s", name, err)
+			continue
+		}
+		v := q.Get("name_elements")
+		if strings.ContainsAny(name, `,:;"`) && v != quote(name) {
+			t.Errorf("%q: listed as %q, expected quoted", name, v)
+		}
+		if u := unquote(v); u != name {
+			t.Errorf("%q: listed as %q", name, u)
+		}
+	}
+	p := params{}
+	p.List("name_elements", nastyNames...)
+	q, err := url.ParseQuery(p.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []string
+	for _, name := range nastyNames {
+		if strings.ContainsAny(name, `,:;"`) {
+			name = quote(name)
+		}
+		items = append(items, name)
+	}
+	if v, ex := q.Get("name_elements"), strings.Join(items, ","); v != ex {
+		t.Errorf("list decoded as %q, expected %q", v, ex)
+	}
+}
+
+func TestNamesCsv(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, name := range nastyNames {
+		if err := w.Write([]string{"1", name, quote(name)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(nastyNames) {
+		t.Fatalf("%d records read, expected %d", len(records), len(nastyNames))
+	}
+	for i, name := range nastyNames {
+		r := records[i]
+		if len(r) != 3 || r[0] != "1" || r[1] != name || r[2] != quote(name) {
+			t.Errorf("%q: read as %q", name, r)
+		}
+	}
+}
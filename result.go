@@ -1,11 +1,8 @@
 package cube
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
 	"fmt"
-	"io"
 	"reflect"
 	"strconv"
 	"strings"
@@ -19,40 +16,17 @@ func internalErr(msg string) *PaloError {
 	return &PaloError{Message: msg}
 }
 
-// Creates a new resultRow object from a string.
-func newResultRow(s string) (row resultRow, err error) {
-	r := bufio.NewReader(bytes.NewBufferString(s))
-	for err == nil {
-		var b byte
-		if p, err := r.Peek(1); err != nil {
-			break
-		} else {
-			b = p[0]
-		}
-		end := _semicolon
-		if b == _quote {
-			r.ReadByte()
-			end = _quote
-		}
-		v, err := r.ReadString(byte(end))
-		if err != nil {
-			break
-		}
-		if b == '"' {
-			b, err = r.ReadByte()
-			if err != nil {
-				break
-			}
-			if b != byte(';') {
-				return nil, fmt.Errorf("unexpected %q", rune(b))
-			}
-		}
-		row = append(row, resultField(strings.Trim(v, `;"`)))
-	}
-	if err != nil && err != io.EOF {
-		return nil, err
+func wrapErr(msg string, err error) *PaloError {
+	return &PaloError{Message: fmt.Sprintf("%s: %s", msg, err), err: err}
+}
+
+// Creates a new resultRow object from a csv record.
+func newRecordRow(record []string) resultRow {
+	var row = make(resultRow, len(record))
+	for i := range record {
+		row[i] = resultField(record[i])
 	}
-	return row, nil
+	return row
 }
 
 // A row of the palo response.
@@ -110,7 +84,7 @@ func (p resultRow) Unmarshal(v interface{}) error {
 			for _, s := range p[j].Array() {
 				a, err := getValue(et, s)
 				if err != nil {
-					return fmt.Errorf("field %s: %s", f.Name, err)
+					return fmt.Errorf("field %s: %w", f.Name, err)
 				}
 				v = reflect.Append(v, reflect.ValueOf(a))
 			}
@@ -185,6 +159,3 @@ func (f resultField) Array() []string {
 	}
 	return strings.Split(string(f), ",")
 }
-
-const _quote = '"'
-const _semicolon = ';'
@@ -0,0 +1,136 @@
+package cube
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Returned (wrapped) by the requests sent while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// The policy used to repeat requests that failed for network errors or server failures (5xx).
+type RetryPolicy struct {
+	MaxAttempts int           // Total number of attempts, no retry if lower than 2
+	BaseDelay   time.Duration // Delay before the first retry, doubled at every attempt
+	MaxDelay    time.Duration // Maximum delay between attempts, no limit if 0
+	Jitter      float64       // Random fraction of the delay added or removed, from 0 to 1
+	Writes      bool          // Repeats also the requests that change data
+}
+
+// Return the delay before the given retry (starting from 1).
+func (r RetryPolicy) delay(retry int) time.Duration {
+	if retry > 30 {
+		retry = 30
+	}
+	d := r.BaseDelay << uint(retry-1)
+	if d < 0 || r.MaxDelay > 0 && d > r.MaxDelay {
+		d = r.MaxDelay
+	}
+	if r.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * r.Jitter * float64(d))
+	}
+	return d
+}
+
+// Functions called by the client on retries and circuit breaker changes.
+type Hooks struct {
+	OnRetry   func(endpoint string, attempt int, delay time.Duration, err error)
+	OnBreaker func(open bool)
+}
+
+// Repeats the requests that fail for transient errors using the policy.
+func WithRetry(r RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = r
+	}
+}
+
+// Fails fast with ErrCircuitOpen for the cooldown after threshold consecutive transient failures.
+// A threshold lower than 1 is treated as 1.
+func WithBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *client) {
+		if threshold < 1 {
+			threshold = 1
+		}
+		c.breaker = &breaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+// Sets the functions called on retries and circuit breaker changes.
+func WithHooks(h Hooks) Option {
+	return func(c *client) {
+		c.hooks = h
+	}
+}
+
+// A circuit breaker that counts consecutive transient failures.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+// True if a request can be sent.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !time.Now().Before(b.openUntil)
+}
+
+// Records the result of a request, returns true if the breaker state changed.
+func (b *breaker) record(failed bool) (changed, open bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		changed = b.failures >= b.threshold
+		b.failures = 0
+		return changed, false
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		return b.failures == b.threshold, true
+	}
+	return false, false
+}
+
+// Sends the request through the circuit breaker, repeating it according to the retry policy.
+func (c *client) retry(ctx context.Context, url string, p params) ([]resultRow, *PaloError) {
+	attempts := c.retryPolicy.MaxAttempts
+	if !readEndpoints[url] && !c.retryPolicy.Writes || attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		if c.breaker != nil && !c.breaker.allow() {
+			return nil, wrapErr("request error", ErrCircuitOpen)
+		}
+		rows, pe := c.send(ctx, url, p)
+		failed := pe != nil && pe.transient
+		if c.breaker != nil {
+			if changed, open := c.breaker.record(failed); changed && c.hooks.OnBreaker != nil {
+				c.hooks.OnBreaker(open)
+			}
+		}
+		if !failed || attempt >= attempts {
+			return rows, pe
+		}
+		// no retry while the breaker is open, the next attempt would fail anyway
+		if c.breaker != nil && !c.breaker.allow() {
+			return rows, pe
+		}
+		d := c.retryPolicy.delay(attempt)
+		if c.hooks.OnRetry != nil {
+			c.hooks.OnRetry(url, attempt, d, pe)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, wrapErr("request error", ctx.Err())
+		case <-time.After(d):
+		}
+	}
+}
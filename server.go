@@ -0,0 +1,114 @@
+package cube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// A connection to a Palo server, its session is shared by every database and cube opened with it.
+type Server struct {
+	client *client
+}
+
+// Connects to the server and logs in, the Db field of the configuration is ignored.
+func Connect(ctx context.Context, c Config, opts ...Option) (*Server, error) {
+	client, err := newClient(ctx, c, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{client: client}, nil
+}
+
+// Logs out from the server, the server and the objects opened with it cannot be used afterwards.
+func (s *Server) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+// Logs out from the server, the request is bound to the context.
+func (s *Server) CloseContext(ctx context.Context) error {
+	return s.client.Close(ctx)
+}
+
+// Return the list of databases of the server.
+func (s *Server) Databases(ctx context.Context) ([]*Database, error) {
+	rows, err := s.client.doRequest(ctx, "/server/databases", nil)
+	if err != nil {
+		return nil, fmt.Errorf("databases: %w", err)
+	}
+	var dbs []*Database
+	for i := 0; i < len(rows); i++ {
+		var db Database
+		err := rows[i].Unmarshal(&db)
+		if err != nil {
+			return nil, fmt.Errorf("databases: bad row %d (%w)", i, err)
+		}
+		db.server = s
+		dbs = append(dbs, &db)
+	}
+	return dbs, nil
+}
+
+// Return the list of databases names.
+func (s *Server) DatabaseNames(ctx context.Context) ([]string, error) {
+	dbs, err := s.Databases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var r []string
+	for _, db := range dbs {
+		r = append(r, db.Name())
+	}
+	return r, nil
+}
+
+// Return a database by its name.
+func (s *Server) Database(ctx context.Context, name string) (*Database, error) {
+	dbs, err := s.Databases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, db := range dbs {
+		if db.Name() == name {
+			return db, nil
+		}
+	}
+	return nil, ErrMissDb(name)
+}
+
+// Creates a new normal database with the given name.
+func (s *Server) CreateDatabase(ctx context.Context, name string) (*Database, error) {
+	p := params{}
+	p.Add("new_name", name)
+	p.Add("type", "0")
+	rows, err := s.client.doRequest(ctx, "/database/create", p)
+	if err != nil {
+		return nil, err
+	}
+	db := Database{server: s}
+	if err := db.update(rows); err != nil {
+		return nil, err
+	}
+	return &db, nil
+}
+
+// Executes a request to any endpoint of the server and returns the rows, use Decoder to read them.
+// The session is added to the parameters and renewed if expired, multiple values are joined with commas.
+func (s *Server) Do(ctx context.Context, path string, values url.Values) ([][]string, error) {
+	p := params{}
+	for k, v := range values {
+		p.Add(k, v...)
+	}
+	rows, err := s.client.doRequest(ctx, path, p)
+	if err != nil {
+		return nil, err
+	}
+	var r = make([][]string, len(rows))
+	for i := range rows {
+		r[i] = make([]string, len(rows[i]))
+		for j := range rows[i] {
+			r[i][j] = rows[i][j].String()
+		}
+	}
+	return r, nil
+}
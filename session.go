@@ -0,0 +1,97 @@
+package cube
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Returned (wrapped) by the requests of a closed server.
+var ErrClosed = errors.New("connection closed")
+
+const defaultMaxRelogin = 1
+
+// Sets how many times a request renews an expired session before failing.
+func WithMaxRelogin(n int) Option {
+	return func(c *client) {
+		c.maxRelogin = n
+	}
+}
+
+// The session of a client, shared by concurrent requests.
+type session struct {
+	mu         sync.RWMutex
+	sid        string
+	gen        int // incremented at every login
+	closed     bool
+	maxRelogin int
+}
+
+// Return the current session id and its generation.
+func (c *client) current() (string, int, *PaloError) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return "", 0, wrapErr("session", ErrClosed)
+	}
+	return c.sid, c.gen, nil
+}
+
+// Logs in and replaces the session.
+func (c *client) Login(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.login(ctx)
+}
+
+// Logs in again if the session generation is still gen, so concurrent requests share one new login.
+func (c *client) relogin(ctx context.Context, gen int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	if c.gen != gen {
+		return nil
+	}
+	return c.login(ctx)
+}
+
+// Logs in, the caller must hold the lock.
+func (c *client) login(ctx context.Context) error {
+	p := make(params)
+	p.Add("user", c.conf.User)
+	p.Add("password", c.conf.Pwd)
+	rows, err := c.send(ctx, "/server/login", p)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return internalErr("no login row")
+	}
+	var loginData struct {
+		Data struct{ Session, Time string }
+	}
+	if err := rows[0].Unmarshal(&loginData); err != nil {
+		return err
+	}
+	c.sid = loginData.Data.Session
+	c.gen++
+	return nil
+}
+
+// Ends the session on the server, the client cannot be used afterwards.
+func (c *client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	p := params{}
+	p.Set("sid", c.sid)
+	if _, err := c.send(ctx, "/server/logout", p); err != nil {
+		return err
+	}
+	return nil
+}
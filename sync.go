@@ -0,0 +1,258 @@
+package cube
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// A node of the hierarchy used by Dim.Sync.
+// Nodes with children are consolidated, an element can appear under more parents.
+type Node struct {
+	Name     string
+	Type     int     // Type of a base element (ElemNumeric or ElemString), numeric if 0
+	Weight   float64 // Weight of the element in the parent, 1 if 0
+	Children []*Node
+}
+
+// The kind of a synchronization operation.
+type SyncKind int
+
+const (
+	SyncCreate   SyncKind = iota // Creates a new element
+	SyncRetype                   // Changes the type of a base element
+	SyncReparent                 // Replaces the children of an element, moving elements between parents
+	SyncReweight                 // Changes the weights of the children of an element
+	SyncDelete                   // Removes an element
+)
+
+func (k SyncKind) String() string {
+	switch k {
+	case SyncCreate:
+		return "create"
+	case SyncRetype:
+		return "retype"
+	case SyncReparent:
+		return "reparent"
+	case SyncReweight:
+		return "reweight"
+	case SyncDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// An operation of a synchronization plan.
+type SyncOp struct {
+	Kind     SyncKind
+	Name     string    // Name of the element
+	Type     int       // New type of the element (create, retype and reparent)
+	Children []string  // New children of the element (reparent and reweight)
+	Weights  []float64 // New weights of the children (reparent and reweight)
+}
+
+func (o SyncOp) String() string {
+	return fmt.Sprintf("<%s %q type:%d children:%q weights:%v>", o.Kind, o.Name, o.Type, o.Children, o.Weights)
+}
+
+// Options for Dim.Sync.
+type SyncOptions struct {
+	DryRun bool // Returns the plan without applying it
+}
+
+type syncTarget struct {
+	typ      int
+	children []string
+	weights  []float64
+}
+
+// Flattens the tree in a map of targets by element name.
+func syncTargets(nodes []*Node, m map[string]*syncTarget) error {
+	for _, n := range nodes {
+		t := syncTarget{typ: n.Type}
+		if t.typ == 0 {
+			t.typ = ElemNumeric
+		}
+		if len(n.Children) > 0 {
+			t.typ = ElemConsolidated
+			for _, c := range n.Children {
+				w := c.Weight
+				if w == 0 {
+					w = 1
+				}
+				t.children = append(t.children, c.Name)
+				t.weights = append(t.weights, w)
+			}
+		}
+		if old, ok := m[n.Name]; ok {
+			switch {
+			case len(t.children) == 0 && old.typ == ElemConsolidated:
+				// a reference to an element defined elsewhere
+				continue
+			case len(old.children) == 0 && len(t.children) > 0:
+				// the definition of an element referenced before
+			case old.typ != t.typ || !equalStrings(old.children, t.children) || !equalFloats(old.weights, t.weights):
+				return fmt.Errorf("element %q has conflicting definitions", n.Name)
+			default:
+				continue
+			}
+		}
+		m[n.Name] = &t
+		if err := syncTargets(n.Children, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Computes the operations needed to make the dimension hierarchy equal to the given tree.
+func (d *Dim) syncPlan(tree []*Node) ([]SyncOp, error) {
+	var targets = make(map[string]*syncTarget)
+	if err := syncTargets(tree, targets); err != nil {
+		return nil, err
+	}
+	var creates, retypes, links, deletes []SyncOp
+	var names []string
+	var seen = make(map[string]bool)
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, n := range nodes {
+			if !seen[n.Name] {
+				seen[n.Name] = true
+				names = append(names, n.Name)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(tree)
+	for _, name := range names {
+		t := targets[name]
+		var current []string
+		var weights []float64
+		a := d.elems.Name(name)
+		if a == nil {
+			creates = append(creates, SyncOp{Kind: SyncCreate, Name: name, Type: t.typ})
+		} else {
+			el := a.(*Elem)
+			for i, c := range el.children {
+				w := 1.0
+				if i < len(el.Data.Weights) {
+					w = el.Data.Weights[i]
+				}
+				current = append(current, c.Name())
+				weights = append(weights, w)
+			}
+			if t.typ != ElemConsolidated && el.Data.Type != t.typ {
+				retypes = append(retypes, SyncOp{Kind: SyncRetype, Name: name, Type: t.typ})
+			}
+		}
+		if t.typ != ElemConsolidated {
+			continue
+		}
+		op := SyncOp{Kind: SyncReparent, Name: name, Type: t.typ, Children: t.children, Weights: t.weights}
+		if !equalStrings(current, t.children) {
+			links = append(links, op)
+		} else if !equalFloats(weights, t.weights) {
+			op.Kind = SyncReweight
+			links = append(links, op)
+		}
+	}
+	for _, el := range d.sortedElems() {
+		if _, ok := targets[el.Name()]; !ok {
+			deletes = append(deletes, SyncOp{Kind: SyncDelete, Name: el.Name()})
+		}
+	}
+	var plan []SyncOp
+	for _, ops := range [][]SyncOp{creates, retypes, links, deletes} {
+		plan = append(plan, ops...)
+	}
+	return plan, nil
+}
+
+// Makes the dimension hierarchy equal to the given tree applying the minimal set of operations:
+// creates, type changes, children replacements (moves and re-parents), weights changes and deletes.
+// Returns the plan, with DryRun the plan is not applied.
+func (d *Dim) Sync(ctx context.Context, tree []*Node, opts SyncOptions) ([]SyncOp, error) {
+	if d.elems.Empty() {
+		err := d.init(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	plan, err := d.syncPlan(tree)
+	if err != nil || opts.DryRun {
+		return plan, err
+	}
+	var specs []ElemSpec
+	for _, op := range plan {
+		if op.Kind == SyncCreate {
+			specs = append(specs, ElemSpec{Name: op.Name, Type: op.Type})
+		}
+	}
+	if len(specs) > 0 {
+		if err := d.AddElems(ctx, specs); err != nil {
+			return plan, err
+		}
+	}
+	var deleted bool
+	for _, op := range plan {
+		switch op.Kind {
+		case SyncRetype, SyncReparent, SyncReweight:
+			el, err := d.Elem(op.Name)
+			if err != nil {
+				return plan, err
+			}
+			var children []*Elem
+			for _, n := range op.Children {
+				c, err := d.Elem(n)
+				if err != nil {
+					return plan, err
+				}
+				children = append(children, c)
+			}
+			if err := el.Replace(ctx, op.Type, children, op.Weights); err != nil {
+				return plan, fmt.Errorf("%s %q: %w", op.Kind, op.Name, err)
+			}
+		case SyncDelete:
+			el, err := d.Elem(op.Name)
+			if err != nil {
+				return plan, err
+			}
+			p := params{}
+			p.Add("dimension", strconv.Itoa(d.Data.Id))
+			p.Add("element", strconv.Itoa(el.Id()))
+			if _, pErr := d.doRequest(ctx, "/element/destroy", p); pErr != nil {
+				return plan, fmt.Errorf("%s %q: %w", op.Kind, op.Name, pErr)
+			}
+			deleted = true
+		}
+	}
+	if deleted {
+		return plan, d.init(ctx)
+	}
+	return plan, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}